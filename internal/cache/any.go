@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrTypeMismatch = errors.New("value has a different type")
+
+type Any struct {
+	*Cache[any]
+}
+
+func NewAny(c *Cache[any]) *Any {
+	return &Any{Cache: c}
+}
+
+func (a *Any) Get(key string) (any, bool) {
+	val, err := a.Cache.Get(key)
+	if err != nil {
+		return nil, false
+	}
+
+	return val, true
+}
+
+func (a *Any) GetString(key string) (string, error) {
+	return GetAs[string](a, key)
+}
+
+func (a *Any) GetInt(key string) (int, error) {
+	return GetAs[int](a, key)
+}
+
+func (a *Any) GetInt64(key string) (int64, error) {
+	return GetAs[int64](a, key)
+}
+
+func (a *Any) GetFloat64(key string) (float64, error) {
+	return GetAs[float64](a, key)
+}
+
+func (a *Any) GetBool(key string) (bool, error) {
+	return GetAs[bool](a, key)
+}
+
+func (a *Any) GetDuration(key string) (time.Duration, error) {
+	return GetAs[time.Duration](a, key)
+}
+
+func GetAs[T any](a *Any, key string) (T, error) {
+	val, err := a.Cache.Get(key)
+	if err != nil {
+		return zero[T](), err
+	}
+
+	typed, ok := val.(T)
+	if !ok {
+		return zero[T](), fmt.Errorf("%w: key %s has type %T, not %T", ErrTypeMismatch, key, val, typed)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,60 @@
+package cache
+
+import "context"
+
+type QueuePolicy int
+
+const (
+	QueueBlock QueuePolicy = iota
+	QueueDrop
+)
+
+type evictEvent struct {
+	ctx  context.Context
+	key  string
+	call func(ctx context.Context)
+}
+
+func (c *Cache[T]) startEvictionWorker() {
+	queue := make(chan evictEvent, c.evictBuffer)
+	done := make(chan struct{})
+	c.evictQueue = queue
+	c.evictDone = done
+
+	go func() {
+		defer close(done)
+
+		for ev := range queue {
+			c.guard("OnEvicted", func() { c.callEvicted(ev.ctx, ev.key, ev.call) })
+		}
+	}()
+}
+
+func (c *Cache[T]) enqueueEvicted(ev evictEvent) {
+	if c.evictPolicy == QueueBlock {
+		c.evictQueue <- ev
+		return
+	}
+
+	select {
+	case c.evictQueue <- ev:
+	default:
+		c.droppedEvictions.Add(1)
+
+		if c.logger != nil {
+			c.logger.Warn("memo: eviction queue is full, callback dropped", "key", ev.key)
+		}
+	}
+}
+
+func (c *Cache[T]) stopEvictionWorker() <-chan struct{} {
+	if c.evictQueue == nil {
+		return nil
+	}
+
+	close(c.evictQueue)
+	done := c.evictDone
+	c.evictQueue, c.evictDone = nil, nil
+
+	return done
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type breaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	firstFail time.Time
+	openUntil time.Time
+	halfOpen  bool
+
+	trips         atomic.Uint64
+	shortCircuits atomic.Uint64
+}
+
+func (b *breaker) reset() {
+	b.mu.Lock()
+	b.failures = 0
+	b.firstFail = time.Time{}
+	b.openUntil = time.Time{}
+	b.halfOpen = false
+	b.mu.Unlock()
+
+	b.trips.Store(0)
+	b.shortCircuits.Store(0)
+}
+
+func (b *breaker) allow(now time.Time) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.openUntil) {
+		b.shortCircuits.Add(1)
+		return false
+	}
+
+	if !b.openUntil.IsZero() {
+		b.openUntil = time.Time{}
+		b.halfOpen = true
+	}
+
+	return true
+}
+
+func (b *breaker) record(err error, now time.Time, logger *slog.Logger) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.halfOpen = false
+		return
+	}
+
+	if b.failures == 0 || (b.window > 0 && now.Sub(b.firstFail) > b.window) {
+		b.failures = 0
+		b.firstFail = now
+	}
+	b.failures++
+
+	if b.failures < b.threshold && !b.halfOpen {
+		return
+	}
+
+	b.failures = 0
+	b.halfOpen = false
+	b.openUntil = now.Add(b.cooldown)
+	b.trips.Add(1)
+
+	if logger != nil {
+		logger.Warn("memo: loader circuit opened", "cooldown", b.cooldown)
+	}
+}
+
+func (b *breaker) isOpen(now time.Time) bool {
+	if b.threshold <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return now.Before(b.openUntil)
+}
+
+func (c *Cache[T]) peek(key string) (T, bool) {
+	k := c.key(key)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return zero[T](), false
+	}
+
+	item, exists := c.items[k]
+	if !exists {
+		return zero[T](), false
+	}
+
+	return c.copyValue(c.value(item)), true
+}
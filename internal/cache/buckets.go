@@ -0,0 +1,83 @@
+package cache
+
+import "time"
+
+func (c *Cache[T]) resetBuckets() {
+	c.swept = 0
+	if c.strategy == StrategyLazy || c.maxIdle > 0 {
+		c.buckets = nil
+		return
+	}
+
+	c.buckets = make(map[int64]map[string]*Item[T])
+}
+
+func (c *Cache[T]) bucketAdd(k string, item *Item[T]) {
+	if c.buckets == nil {
+		return
+	}
+
+	due := item.TTL.Add(c.stale)
+	if c.maxAge > 0 && !item.setAt.IsZero() {
+		if byAge := item.setAt.Add(c.maxAge).Add(c.stale); byAge.Before(due) {
+			due = byAge
+		}
+	}
+
+	item.bucket = max(due.Unix(), c.swept)
+
+	b, exists := c.buckets[item.bucket]
+	if !exists {
+		b = make(map[string]*Item[T])
+		c.buckets[item.bucket] = b
+	}
+	b[k] = item
+}
+
+func (c *Cache[T]) bucketRemove(k string, item *Item[T]) {
+	b, exists := c.buckets[item.bucket]
+	if !exists || b[k] != item {
+		return
+	}
+
+	delete(b, k)
+	if len(b) == 0 {
+		delete(c.buckets, item.bucket)
+	}
+}
+
+func (c *Cache[T]) dueBuckets(now time.Time) []int64 {
+	sec := now.Unix()
+
+	var due []int64
+	if sec-c.swept > int64(len(c.buckets)) {
+		for s := range c.buckets {
+			if s <= sec {
+				due = append(due, s)
+			}
+		}
+		return due
+	}
+
+	for s := c.swept; s <= sec; s++ {
+		if _, exists := c.buckets[s]; exists {
+			due = append(due, s)
+		}
+	}
+
+	return due
+}
+
+func (c *Cache[T]) dueItems(now time.Time) []candidate[T] {
+	var due []candidate[T]
+	for _, s := range c.dueBuckets(now) {
+		for k, v := range c.buckets[s] {
+			if c.removable(v, now) {
+				due = append(due, candidate[T]{key: k, value: v})
+			}
+		}
+	}
+	c.swept = now.Unix()
+
+	return due
+}
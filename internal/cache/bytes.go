@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+	"weak"
+
+	"github.com/crewcrew23/memo/internal/stat"
+)
+
+const (
+	slabSize     = 64 << 10
+	maxSlabValue = slabSize / 8
+)
+
+type ByteCache struct {
+	cache *Cache[[]byte]
+
+	mu    sync.Mutex
+	slab  []byte
+	slabs []weak.Pointer[[slabSize]byte]
+}
+
+func NewByteCache(ctx context.Context, cancel context.CancelFunc, opts ...Option[[]byte]) *ByteCache {
+	opts = append([]Option[[]byte]{WithSizer(func(b []byte) int64 {
+		return int64(cap(b))
+	})}, opts...)
+
+	return &ByteCache{cache: New(ctx, cancel, opts...)}
+}
+
+func (b *ByteCache) Cache() *Cache[[]byte] {
+	return b.cache
+}
+
+func (b *ByteCache) Set(key string, value []byte, ttl time.Duration) error {
+	return b.cache.Set(key, b.alloc(value), ttl)
+}
+
+func (b *ByteCache) SetWithContext(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.cache.SetWithContext(ctx, key, b.alloc(value), ttl)
+}
+
+func (b *ByteCache) GetRef(key string) ([]byte, error) {
+	return b.cache.Get(key)
+}
+
+func (b *ByteCache) GetCopy(key string) ([]byte, error) {
+	val, err := b.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), val...), nil
+}
+
+func (b *ByteCache) Has(key string) bool {
+	return b.cache.Has(key)
+}
+
+func (b *ByteCache) Delete(key string) error {
+	return b.cache.Delete(key)
+}
+
+func (b *ByteCache) Stat() stat.Stats {
+	st := b.cache.Stat()
+	st.SizeBytes = b.retained()
+	return st
+}
+
+func (b *ByteCache) Close() error {
+	return b.cache.Close()
+}
+
+func (b *ByteCache) alloc(value []byte) []byte {
+	if len(value) == 0 || len(value) > maxSlabValue {
+		return append(make([]byte, 0, len(value)), value...)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(value) > cap(b.slab)-len(b.slab) {
+		slab := new([slabSize]byte)
+		b.slab = slab[:0]
+		b.slabs = append(b.slabs, weak.Make(slab))
+	}
+
+	start := len(b.slab)
+	b.slab = append(b.slab, value...)
+
+	return b.slab[start:len(b.slab):len(b.slab)]
+}
+
+// retained returns the bytes kept alive by the stored values: a whole slab
+// for every slab that still holds a value, and the capacity of every value
+// that has its own allocation. Slabs without values are forgotten, except
+// the one being filled.
+func (b *ByteCache) retained() int64 {
+	c := b.cache
+	if err := c.rlock(); err != nil {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	slabs := make([]*[slabSize]byte, 0, len(b.slabs))
+	for _, w := range b.slabs {
+		if slab := w.Value(); slab != nil {
+			slabs = append(slabs, slab)
+		}
+	}
+	sort.Slice(slabs, func(i, j int) bool {
+		return uintptr(unsafe.Pointer(slabs[i])) < uintptr(unsafe.Pointer(slabs[j]))
+	})
+
+	used := make([]bool, len(slabs))
+	var size int64
+	for _, item := range c.items {
+		if cap(item.Value) == 0 {
+			continue
+		}
+
+		p := uintptr(unsafe.Pointer(unsafe.SliceData(item.Value)))
+		i := sort.Search(len(slabs), func(i int) bool {
+			return uintptr(unsafe.Pointer(slabs[i]))+slabSize > p
+		})
+
+		if i < len(slabs) && uintptr(unsafe.Pointer(slabs[i])) <= p {
+			used[i] = true
+		} else {
+			size += int64(cap(item.Value))
+		}
+	}
+
+	b.slabs = b.slabs[:0]
+	for i, slab := range slabs {
+		if used[i] {
+			size += slabSize
+		}
+
+		if used[i] || (cap(b.slab) > 0 && unsafe.SliceData(b.slab) == &slab[0]) {
+			b.slabs = append(b.slabs, weak.Make(slab))
+		}
+	}
+
+	return size
+}
@@ -2,42 +2,218 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"reflect"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/crewcrew23/memo/internal/stat"
 )
 
 type Item[T any] struct {
-	Value T         `json:"value"`
-	TTL   time.Time `json:"ttl"`
+	Value   T         `json:"value"`
+	TTL     time.Time `json:"ttl"`
+	setAt   time.Time
+	version uint64
+	weight  int64
+	size    int64
+
+	packed     []byte
+	compressed bool
+
+	pinned     bool
+	bucket     int64
+	lastAccess atomic.Int64
+}
+
+type KV[T any] struct {
+	Key   string
+	Value T
 }
 
 type Cache[T any] struct {
-	items     map[string]*Item[T]
-	mu        sync.RWMutex
-	ctx       context.Context
-	cancel    context.CancelFunc
-	onEvicted func(string, T)
-	stat      *stat.Stats
-	sizeof    int64
+	items      map[string]*Item[T]
+	mu         sync.RWMutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+	onEvicted  func(string, T)
+	onSet      func(string, T, time.Duration)
+	onEvictCtx func(context.Context, string, T)
+
+	callbackTimeout  time.Duration
+	callbackTimeouts atomic.Uint64
+
+	evictBuffer      int
+	evictPolicy      QueuePolicy
+	evictQueue       chan evictEvent
+	evictDone        chan struct{}
+	droppedEvictions atomic.Uint64
+	onBatch          func([]KV[T])
+	onCapacity       func(string, T) error
+	closed           ClosedPolicy
+	weight           int64
+	keyBytes         int64
+	maxWeight        int64
+	maxEntries       int
+	maxKeyLen        int
+	growth           growthAlert
+	duplicates       DuplicatePolicy
+	policy           atomic.Int32
+	maxIdle          time.Duration
+	sampleSize       int
+	stat             *stat.Stats
+	hits             atomic.Uint64
+	misses           atomic.Uint64
+	statsOff         bool
+	deepCopy         bool
+	clone            func(T) T
+	equals           func(a, b T) bool
+	stale            time.Duration
+	window           *stat.Window
+	keyFn            func(string) string
+	maxAge           time.Duration
+	ttlPrecision     time.Duration
+	defaultTimeout   time.Duration
+	lockStats        lockStats
+	rejectPast       bool
+	minTTL           time.Duration
+	maxTTL           time.Duration
+	version          uint64
+
+	persistPath      string
+	autoPersistPath  string
+	autoPersistEvery time.Duration
+	restorePath      string
+	lastPersist      atomic.Int64
+	persistedBytes   atomic.Int64
+	drain            func(string, T)
+	cleanInterval    time.Duration
+	strategy         CleanStrategy
+
+	shardCount  int
+	shardHasher func(string) uint64
+
+	clock    Clock
+	logger   *slog.Logger
+	logEvery int
+
+	loader          TTLLoader[T]
+	loaderTTL       time.Duration
+	refreshWorkers  int
+	refreshMu       sync.Mutex
+	refreshing      map[string]struct{}
+	refreshQueue    chan string
+	refreshQueued   atomic.Int64
+	refreshInFlight atomic.Int64
+	loadSem         chan struct{}
+	busy            BusyPolicy
+	loadsInFlight   atomic.Int64
+	retryAttempts   int
+	retryDelay      time.Duration
+
+	breaker breaker
+
+	panicsRecovered atomic.Uint64
+
+	cleaners  atomic.Int32
+	lastSweep atomic.Int64
+
+	codec Codec
+	sizer func(T) int64
+
+	compress   func(T) ([]byte, bool)
+	decompress func([]byte) (T, error)
+
+	indexes map[string]*index[T]
+
+	buckets map[int64]map[string]*Item[T]
+	swept   int64
+
+	flightMu sync.Mutex
+	flights  map[string]*flight[T]
 }
 
-func New[T any](ctx context.Context, cancel context.CancelFunc) *Cache[T] {
-	return &Cache[T]{
+func New[T any](ctx context.Context, cancel context.CancelFunc, opts ...Option[T]) *Cache[T] {
+	c := &Cache[T]{
 		items:  make(map[string]*Item[T]),
 		ctx:    ctx,
 		cancel: cancel,
 		stat:   &stat.Stats{},
+		window: stat.NewWindow(time.Minute, 60),
+		clock:  realClock{},
+		codec:  JSONCodec{},
+
+		refreshing: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.resetBuckets()
+
+	if c.restorePath != "" {
+		if err := c.restoreFile(c.restorePath); err != nil && c.logger != nil {
+			c.logger.Warn("memo: restore on start failed", "path", c.restorePath, "error", err)
+		}
+	}
+
+	if c.evictBuffer > 0 {
+		c.startEvictionWorker()
+	}
+
+	if c.ctx == nil && (c.autoPersistEvery > 0 || c.refreshWorkers > 0) {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+	}
+
+	if c.autoPersistEvery > 0 {
+		c.startAutoPersist(c.ctx)
+	}
+
+	if c.refreshWorkers > 0 {
+		c.startRefreshWorkers(c.refreshWorkers)
 	}
+
+	return c
+}
+
+func From[T any](src *Cache[T], ctx context.Context, cancel context.CancelFunc, opts ...Option[T]) *Cache[T] {
+	c := New[T](ctx, cancel, opts...)
+
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+
+	now := c.now()
+	for k, v := range src.items {
+		if c.expired(v, now) {
+			continue
+		}
+
+		item := &Item[T]{
+			Value:  src.value(v),
+			TTL:    c.truncateTTL(c.capAge(v.TTL, v.setAt)),
+			setAt:  v.setAt,
+			weight: v.weight,
+			pinned: v.pinned,
+		}
+		item.lastAccess.Store(v.lastAccess.Load())
+		c.prepare(item)
+
+		c.items[k] = item
+		c.bucketAdd(k, item)
+		c.weight += v.weight
+		c.keyBytes += int64(len(k))
+		c.stat.SizeBytes += item.size
+	}
+
+	return c
 }
 
 func (c *Cache[T]) OnEvicted(fn func(key string, value T)) error {
-	c.mu.Lock()
+	if err := c.lock(); err != nil {
+		return err
+	}
 	defer c.mu.Unlock()
 
 	if c.items == nil {
@@ -48,289 +224,848 @@ func (c *Cache[T]) OnEvicted(fn func(key string, value T)) error {
 	return nil
 }
 
-func (c *Cache[T]) Set(key string, value T, ttl time.Duration) error {
-	c.mu.Lock()
+func (c *Cache[T]) OnEvictedContext(fn func(ctx context.Context, key string, value T)) error {
+	if err := c.lock(); err != nil {
+		return err
+	}
 	defer c.mu.Unlock()
 
 	if c.items == nil {
 		return errors.New("cache is closed")
 	}
 
-	if c.sizeof == 0 {
-		c.sizeof = getSize(value)
+	c.onEvictCtx = fn
+	return nil
+}
+
+func (c *Cache[T]) OnEvictedBatch(fn func(evicted []KV[T])) error {
+	if err := c.lock(); err != nil {
+		return err
 	}
+	defer c.mu.Unlock()
 
-	c.stat.SizeBytes += int64(c.sizeof)
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	c.onBatch = fn
+	return nil
+}
+
+func (c *Cache[T]) OnCapacityEvict(fn func(key string, value T) error) error {
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
 
-	c.items[key] = &Item[T]{
-		Value: value,
-		TTL:   time.Now().Add(ttl),
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	c.onCapacity = fn
+	return nil
+}
+
+func (c *Cache[T]) Set(key string, value T, ttl time.Duration) error {
+	k := c.key(key)
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	var onSet func()
+	defer func() { runCallback(onSet) }()
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return c.closedErr()
+	}
+
+	if c.unchanged(k, value, ttl) {
+		return nil
+	}
+
+	item := c.newItem(value, ttl)
+	if err := c.store(k, item); err != nil {
+		return err
+	}
+
+	onSet = c.setCallback(k, value, item)
+	return nil
+}
+
+func (c *Cache[T]) SetExpireAt(key string, value T, at time.Time) error {
+	k := c.key(key)
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	var onSet func()
+	defer func() { runCallback(onSet) }()
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return c.closedErr()
+	}
+
+	now := c.now()
+	if c.rejectPast && !at.After(now) {
+		return fmt.Errorf("expiry time %s of key %s is in the past", at.Format(time.RFC3339Nano), key)
+	}
+
+	if ttl, clamped := c.clampTTL(at.Sub(now)); clamped {
+		at = now.Add(ttl)
+	}
+
+	item := c.newItemAt(value, now, at)
+	if err := c.store(k, item); err != nil {
+		return err
+	}
+
+	onSet = c.setCallback(k, value, item)
+	return nil
+}
+
+func (c *Cache[T]) SetWithWeight(key string, value T, ttl time.Duration, weight int64) error {
+	k := c.key(key)
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	var onSet func()
+	defer func() { runCallback(onSet) }()
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return c.closedErr()
+	}
+
+	item := c.newItem(value, ttl)
+	item.weight = weight
+
+	if err := c.store(k, item); err != nil {
+		return err
 	}
 
+	onSet = c.setCallback(k, value, item)
 	return nil
 }
 
 func (c *Cache[T]) SetWithContext(ctx context.Context, key string, value T, ttl time.Duration) error {
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-		c.mu.Lock()
-		defer c.mu.Unlock()
+	k := c.key(key)
 
-		if c.items == nil {
-			return errors.New("cache is closed")
-		}
+	if err := c.lockContext(ctx); err != nil {
+		return err
+	}
+	var onSet func()
+	defer func() { runCallback(onSet) }()
+	defer c.mu.Unlock()
 
-		if c.sizeof == 0 {
-			c.sizeof = getSize(value)
-		}
+	if c.items == nil {
+		return c.closedErr()
+	}
 
-		c.stat.SizeBytes += int64(c.sizeof)
+	item := c.newItem(value, ttl)
+	if err := c.store(k, item); err != nil {
+		return err
+	}
 
-		c.items[key] = &Item[T]{
-			Value: value,
-			TTL:   time.Now().Add(ttl),
-		}
+	onSet = c.setCallback(k, value, item)
+	return nil
+}
 
-		return nil
+func (c *Cache[T]) SetOrGet(key string, value T, ttl time.Duration) (T, bool, error) {
+	k := c.key(key)
+
+	var onSet func()
+	defer func() { runCallback(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return zero[T](), false, err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return zero[T](), false, c.closedErr()
+	}
+
+	item, exists := c.items[k]
+	if exists && !c.expired(item, c.now()) {
+		c.touch(item)
+		return c.copyValue(c.value(item)), true, nil
+	}
+
+	item = c.newItem(value, ttl)
+	if err := c.store(k, item); err != nil {
+		return zero[T](), false, err
 	}
+
+	onSet = c.setCallback(k, value, item)
+	return value, false, nil
+}
+
+func (c *Cache[T]) LoadOrStore(key string, value T, ttl time.Duration) (T, bool, error) {
+	return c.SetOrGet(key, value, ttl)
 }
 
 func (c *Cache[T]) Get(key string) (T, error) {
-	c.mu.RLock()
+	k := c.key(key)
+
+	if err := c.rlock(); err != nil {
+		return zero[T](), err
+	}
+
 	if c.items == nil {
+		c.mu.RUnlock()
 		return zero[T](), errors.New("cache is closed")
 	}
 
-	item, exists := c.items[key]
+	item, exists := c.items[k]
 	c.mu.RUnlock()
 
 	if !exists {
-		c.stat.Misses++
+		c.miss()
 		return zero[T](), fmt.Errorf("key %s does not exists", key)
 	}
 
-	if time.Now().After(item.TTL) {
-		c.mu.Lock()
-		if c.onEvicted != nil {
-			c.onEvicted(key, item.Value)
-		}
+	if c.expired(item, c.now()) {
+		c.miss()
+		if c.removable(item, c.now()) {
+			if err := c.lock(); err != nil {
+				return zero[T](), err
+			}
 
-		delete(c.items, key)
+			c.evict(k, item)
+			c.mu.Unlock()
+		}
 
-		c.stat.Evictions++
-		c.stat.SizeBytes -= int64(c.sizeof)
+		return zero[T](), fmt.Errorf("TTL of key %s has expire", key)
+	}
 
-		c.mu.Unlock()
+	c.hit()
+	c.touch(item)
+	return c.copyValue(c.value(item)), nil
+}
 
-		return zero[T](), fmt.Errorf("TTL of key %s has expire", key)
+func (c *Cache[T]) GetOrDefault(key string, def T) T {
+	val, err := c.Get(key)
+	if err != nil {
+		return def
 	}
 
-	c.stat.Hits++
-	return item.Value, nil
+	return val
 }
 
 func (c *Cache[T]) GetWithContext(ctx context.Context, key string) (T, error) {
-	select {
-	case <-ctx.Done():
-		return zero[T](), ctx.Err()
-	default:
-		c.mu.RLock()
-		if c.items == nil {
-			return zero[T](), errors.New("cache is closed")
-		}
+	k := c.key(key)
+
+	if err := c.rlockContext(ctx); err != nil {
+		return zero[T](), err
+	}
 
-		item, exists := c.items[key]
+	if c.items == nil {
 		c.mu.RUnlock()
+		return zero[T](), errors.New("cache is closed")
+	}
 
-		if !exists {
-			c.stat.Misses++
-			return zero[T](), fmt.Errorf("key %s does not exists", key)
-		}
+	item, exists := c.items[k]
+	c.mu.RUnlock()
+
+	if !exists {
+		c.miss()
+		return zero[T](), fmt.Errorf("key %s does not exists", key)
+	}
 
-		if time.Now().After(item.TTL) {
-			c.mu.Lock()
-			if c.onEvicted != nil {
-				c.onEvicted(key, item.Value)
+	if c.expired(item, c.now()) {
+		c.miss()
+		if c.removable(item, c.now()) {
+			if err := c.lockContext(ctx); err != nil {
+				return zero[T](), err
 			}
 
-			delete(c.items, key)
+			c.evictContext(ctx, k, item)
+			c.mu.Unlock()
+		}
 
-			c.stat.Evictions++
-			c.stat.SizeBytes -= int64(c.sizeof)
+		return zero[T](), fmt.Errorf("TTL of key %s has expire", key)
+	}
 
-			c.mu.Unlock()
+	c.hit()
+	c.touch(item)
+	return c.copyValue(c.value(item)), nil
+}
 
-			return zero[T](), fmt.Errorf("TTL of key %s has expire", key)
-		}
+func (c *Cache[T]) Has(key string) bool {
+	k := c.key(key)
 
-		c.stat.Hits++
-		return item.Value, nil
+	if err := c.rlock(); err != nil {
+		return false
 	}
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[k]
+	return exists && !c.expired(item, c.now())
 }
 
-func (c *Cache[T]) MarshalJSON() ([]byte, error) {
-	c.mu.RLock()
+func (c *Cache[T]) GetStale(key string) (T, bool, error) {
+	k := c.key(key)
+
+	if err := c.rlock(); err != nil {
+		return zero[T](), false, err
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return zero[T](), false, errors.New("cache is closed")
+	}
+
+	item, exists := c.items[k]
+	if !exists {
+		c.miss()
+		return zero[T](), false, fmt.Errorf("key %s does not exists", key)
+	}
+
+	stale := c.expired(item, c.now())
+	if stale {
+		c.miss()
+	} else {
+		c.hit()
+	}
+	c.touch(item)
+
+	if stale && c.loader != nil {
+		c.refresh(key)
+	}
+
+	return c.copyValue(c.value(item)), stale, nil
+}
+
+func (c *Cache[T]) GetVersioned(key string) (T, uint64, error) {
+	k := c.key(key)
+
+	if err := c.rlock(); err != nil {
+		return zero[T](), 0, err
+	}
 	defer c.mu.RUnlock()
 
 	if c.items == nil {
+		return zero[T](), 0, errors.New("cache is closed")
+	}
+
+	item, exists := c.items[k]
+	if !exists {
+		c.miss()
+		return zero[T](), 0, fmt.Errorf("key %s does not exists", key)
+	}
+
+	if c.expired(item, c.now()) {
+		c.miss()
+		return zero[T](), 0, fmt.Errorf("TTL of key %s has expire", key)
+	}
+
+	c.hit()
+	c.touch(item)
+	return c.copyValue(c.value(item)), item.version, nil
+}
+
+func (c *Cache[T]) SetVersioned(key string, value T, ttl time.Duration, version uint64) (bool, error) {
+	k := c.key(key)
+
+	var onSet func()
+	defer func() { runCallback(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return false, err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return false, c.closedErr()
+	}
+
+	item, exists := c.items[k]
+	live := exists && !c.expired(item, c.now())
+
+	if live && item.version != version {
+		return false, nil
+	}
+
+	if !live && version != 0 {
+		return false, nil
+	}
+
+	item = c.newItem(value, ttl)
+	if err := c.store(k, item); err != nil {
+		return false, err
+	}
+
+	onSet = c.setCallback(k, value, item)
+	return true, nil
+}
+
+func (c *Cache[T]) MarshalJSON() ([]byte, error) {
+	if err := c.rlock(); err != nil {
+		return nil, err
+	}
+	if c.items == nil {
+		c.mu.RUnlock()
 		return nil, errors.New("cache is closed")
 	}
 
-	serializable := make(map[string]struct {
-		Value T         `json:"value"`
-		TTL   time.Time `json:"ttl"`
-	})
+	entries := c.snapshot()
+	c.mu.RUnlock()
 
+	return JSONCodec{}.Marshal(entries)
+}
+
+// MarshalJSONSorted is MarshalJSON, encoding/json already writes map keys in
+// sorted order.
+func (c *Cache[T]) MarshalJSONSorted() ([]byte, error) {
+	return c.MarshalJSON()
+}
+
+func (c *Cache[T]) MarshalJSONAndPrune() ([]byte, error) {
+	if err := c.lock(); err != nil {
+		return nil, err
+	}
+
+	if c.items == nil {
+		c.mu.Unlock()
+		return nil, errors.New("cache is closed")
+	}
+
+	entries := c.snapshot()
+
+	now := c.now()
 	for k, v := range c.items {
-		serializable[k] = struct {
-			Value T         `json:"value"`
-			TTL   time.Time `json:"ttl"`
-		}{
-			Value: v.Value,
-			TTL:   v.TTL,
+		if c.removable(v, now) {
+			c.evict(k, v)
 		}
 	}
+	c.mu.Unlock()
 
-	return json.Marshal(serializable)
+	return JSONCodec{}.Marshal(entries)
 }
 
 func (c *Cache[T]) MarshalJSONWithContext(ctx context.Context) ([]byte, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		c.mu.RLock()
-		defer c.mu.RUnlock()
+	if err := c.rlockContext(ctx); err != nil {
+		return nil, err
+	}
 
-		if c.items == nil {
-			return nil, errors.New("cache is closed")
-		}
+	if c.items == nil {
+		c.mu.RUnlock()
+		return nil, errors.New("cache is closed")
+	}
 
-		serializable := make(map[string]struct {
-			Value T         `json:"value"`
-			TTL   time.Time `json:"ttl"`
-		})
+	entries := c.snapshot()
+	c.mu.RUnlock()
 
-		for k, v := range c.items {
-			serializable[k] = struct {
-				Value T         `json:"value"`
-				TTL   time.Time `json:"ttl"`
-			}{
-				Value: v.Value,
-				TTL:   v.TTL,
-			}
+	return JSONCodec{}.Marshal(entries)
+}
+
+func (c *Cache[T]) MarshalKeys(keys []string) ([]byte, error) {
+	if err := c.rlock(); err != nil {
+		return nil, err
+	}
+	if c.items == nil {
+		c.mu.RUnlock()
+		return nil, errors.New("cache is closed")
+	}
+
+	serializable := make(map[string]entry[T], len(keys))
+
+	now := c.now()
+	for _, key := range keys {
+		k := c.key(key)
+
+		v, exists := c.items[k]
+		if !exists || c.expired(v, now) {
+			continue
 		}
 
-		return json.Marshal(serializable)
+		serializable[k] = entry[T]{Value: c.value(v), TTL: c.truncateTTL(v.TTL)}
 	}
+	c.mu.RUnlock()
+
+	return JSONCodec{}.Marshal(serializable)
 }
 
 func (c *Cache[T]) UnmarshalJSON(bytes []byte) error {
-	c.mu.Lock()
+	var onSet []func()
+	defer func() { runCallbacks(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return err
+	}
 	defer c.mu.Unlock()
 
 	if c.items == nil {
 		return errors.New("cache is closed")
 	}
 
-	var temp map[string]struct {
-		Value T         `json:"value"`
-		TTL   time.Time `json:"ttl"`
+	var temp map[string]entry[T]
+	if err := (JSONCodec{}).Unmarshal(bytes, &temp); err != nil {
+		return err
 	}
 
-	if err := json.Unmarshal(bytes, &temp); err != nil {
+	var err error
+	onSet, err = c.restore(temp)
+	return err
+}
+
+func (c *Cache[T]) UnmarshalJSONWithContext(ctx context.Context, bytes []byte) error {
+	var onSet []func()
+	defer func() { runCallbacks(onSet) }()
+
+	if err := c.lockContext(ctx); err != nil {
 		return err
 	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	var temp map[string]entry[T]
+	if err := (JSONCodec{}).Unmarshal(bytes, &temp); err != nil {
+		return err
+	}
+
+	var err error
+	onSet, err = c.restore(temp)
+	return err
+}
+
+func (c *Cache[T]) Stat() stat.Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hits, misses := c.hits.Load(), c.misses.Load()
+	total := hits + misses
+	rate := 0.0
+	if total > 0 {
+		rate = float64(hits) / float64(total) * 100
+	}
+
+	return stat.Stats{
+		StatsDisabled: c.statsOff,
+
+		Hits:          hits,
+		Misses:        misses,
+		Evictions:     c.stat.Evictions,
+		HitRate:       rate,
+		RecentHitRate: c.window.HitRate(),
+		SizeBytes:     c.sizeBytes(),
+		Entries:       c.live(),
+		MapSize:       len(c.items),
+		OverheadBytes: c.keyBytes + int64(len(c.items))*entryOverhead[T](),
+
+		CapacityEvictErrors: c.stat.CapacityEvictErrors,
+		SizeUnderflows:      c.stat.SizeUnderflows,
+		ClampedTTLs:         c.stat.ClampedTTLs,
+		RejectedSets:        c.stat.RejectedSets,
+		DroppedEvictions:    c.droppedEvictions.Load(),
+
+		LockWaitSamples: c.lockStats.samples.Load(),
+		LockWaitAvg:     c.lockStats.avg(),
+		LockWaitP99:     c.lockStats.p99(),
+
+		LastPersistAt:  c.persistTime(),
+		PersistedBytes: c.persistedBytes.Load(),
+
+		RefreshQueued:   c.refreshQueued.Load(),
+		RefreshInFlight: c.refreshInFlight.Load(),
+		LoadsInFlight:   c.loadsInFlight.Load(),
+
+		PanicsRecovered:  c.panicsRecovered.Load(),
+		CallbackTimeouts: c.callbackTimeouts.Load(),
+
+		LoaderCircuitOpen:   c.breaker.isOpen(c.now()),
+		LoaderCircuitTrips:  c.breaker.trips.Load(),
+		LoaderShortCircuits: c.breaker.shortCircuits.Load(),
+	}
+}
+
+func (c *Cache[T]) Close() error {
+	c.mu.Lock()
+	err := c.close()
+	done := c.stopEvictionWorker()
+	c.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	return err
+}
 
-	for k, v := range temp {
-		c.items[k] = &Item[T]{
-			Value: v.Value,
-			TTL:   v.TTL,
+func (c *Cache[T]) Closed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.items == nil
+}
+
+func (c *Cache[T]) close() error {
+	if c.items == nil {
+		return nil
+	}
+
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+
+	var err error
+	if c.persistPath != "" {
+		err = c.persist(c.persistPath)
+		if err != nil && c.logger != nil {
+			c.logger.Error("memo: persist on close failed", "path", c.persistPath, "error", err)
 		}
 	}
 
-	return nil
+	if c.drain != nil {
+		now := c.now()
+		for k, v := range c.items {
+			if !c.expired(v, now) {
+				c.guard("DrainOnClose", func() { c.drain(k, c.value(v)) })
+			}
+		}
+	}
 
+	if c.logger != nil {
+		c.logger.Info("memo: cache closed", "entries", len(c.items))
+	}
+
+	c.items = nil
+	c.keyBytes = 0
+	c.resetIndexes()
+	c.buckets = nil
+
+	return err
 }
 
-func (c *Cache[T]) UnmarshalJSONWithContext(ctx context.Context, bytes []byte) error {
+func (c *Cache[T]) remove(k string, item *Item[T]) bool {
+	return c.removeContext(context.Background(), k, item)
+}
+
+func (c *Cache[T]) removeContext(ctx context.Context, k string, item *Item[T]) bool {
+	if !c.unlink(k, item) {
+		return false
+	}
+
+	c.notifyEvicted(ctx, k, item)
+
+	return true
+}
+
+func (c *Cache[T]) notifyEvicted(ctx context.Context, k string, item *Item[T]) {
+	if c.onEvicted == nil && c.onEvictCtx == nil {
+		return
+	}
+
+	value := c.value(item)
+	onEvicted, onEvictCtx := c.onEvicted, c.onEvictCtx
+	call := func(ctx context.Context) {
+		if onEvicted != nil {
+			onEvicted(k, value)
+		}
+
+		if onEvictCtx != nil {
+			onEvictCtx(ctx, k, value)
+		}
+	}
+
+	if c.evictQueue != nil {
+		c.enqueueEvicted(evictEvent{ctx: ctx, key: k, call: call})
+		return
+	}
+
+	c.callEvicted(ctx, k, call)
+}
+
+func (c *Cache[T]) callEvicted(ctx context.Context, k string, call func(ctx context.Context)) {
+	if c.callbackTimeout <= 0 {
+		call(ctx)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.callbackTimeout)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer cancel()
+		c.guard("OnEvicted", func() { call(ctx) })
+	}()
+
 	select {
+	case <-done:
 	case <-ctx.Done():
-		return ctx.Err()
-	default:
-		c.mu.Lock()
-		defer c.mu.Unlock()
+		c.callbackTimeouts.Add(1)
 
-		if c.items == nil {
-			return errors.New("cache is closed")
+		if c.logger != nil {
+			c.logger.Warn("memo: eviction callback timed out", "key", k, "timeout", c.callbackTimeout)
 		}
+	}
+}
 
-		var temp map[string]struct {
-			Value T         `json:"value"`
-			TTL   time.Time `json:"ttl"`
-		}
+func (c *Cache[T]) unlink(k string, item *Item[T]) bool {
+	if c.items[k] != item {
+		return false
+	}
+
+	delete(c.items, k)
+	c.rearmGrowth()
+	c.indexRemove(k, item)
+	c.bucketRemove(k, item)
+	c.keyBytes -= int64(len(k))
+	c.shrink(item.size)
+	c.weight -= item.weight
+
+	return true
+}
+
+func (c *Cache[T]) sizeBytes() int64 {
+	if c.strategy != StrategyLazy || c.items == nil {
+		return c.stat.SizeBytes
+	}
 
-		if err := json.Unmarshal(bytes, &temp); err != nil {
-			return err
+	var size int64
+	now := c.now()
+	for _, v := range c.items {
+		if !c.expired(v, now) {
+			size += v.size
 		}
+	}
 
-		for k, v := range temp {
-			c.items[k] = &Item[T]{
-				Value: v.Value,
-				TTL:   v.TTL,
-			}
+	return size
+}
+
+func (c *Cache[T]) shrink(size int64) {
+	if size > c.stat.SizeBytes {
+		c.stat.SizeUnderflows++
+		c.stat.SizeBytes = 0
+		return
+	}
+
+	c.stat.SizeBytes -= size
+}
+
+func (c *Cache[T]) evict(k string, item *Item[T]) {
+	c.evictContext(context.Background(), k, item)
+}
+
+func (c *Cache[T]) evictContext(ctx context.Context, k string, item *Item[T]) {
+	if c.removeContext(ctx, k, item) {
+		c.stat.Evictions++
+
+		if c.logger != nil {
+			c.logger.Debug("memo: entry evicted", "key", k, "reason", "expired")
 		}
+	}
+}
 
-		return nil
+func (c *Cache[T]) newItem(value T, ttl time.Duration) *Item[T] {
+	ttl, _ = c.clampTTL(ttl)
+
+	now := c.now()
+	return c.newItemAt(value, now, now.Add(ttl))
+}
+
+func (c *Cache[T]) newItemAt(value T, now, expires time.Time) *Item[T] {
+	c.version++
+	item := &Item[T]{
+		Value:   value,
+		TTL:     expires,
+		setAt:   now,
+		version: c.version,
+		weight:  1,
 	}
+	item.lastAccess.Store(now.UnixNano())
+	item.TTL = c.truncateTTL(c.capAge(item.TTL, now))
 
+	return item
 }
 
-func (c *Cache[T]) Stat() stat.Stats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+func (c *Cache[T]) capAge(ttl, setAt time.Time) time.Time {
+	if c.maxAge > 0 && !setAt.IsZero() && ttl.After(setAt.Add(c.maxAge)) {
+		return setAt.Add(c.maxAge)
+	}
 
-	total := c.stat.Hits + c.stat.Misses
-	rate := 0.0
-	if total > 0 {
-		rate = float64(c.stat.Hits) / float64(total) * 100
+	return ttl
+}
+
+func (c *Cache[T]) clampTTL(ttl time.Duration) (time.Duration, bool) {
+	if c.minTTL > 0 && ttl < c.minTTL {
+		c.stat.ClampedTTLs++
+		return c.minTTL, true
 	}
 
-	return stat.Stats{
-		Hits:      c.stat.Hits,
-		Misses:    c.stat.Misses,
-		Evictions: c.stat.Evictions,
-		HitRate:   rate,
-		SizeBytes: c.stat.SizeBytes,
+	if c.maxTTL > 0 && ttl > c.maxTTL {
+		c.stat.ClampedTTLs++
+		return c.maxTTL, true
 	}
+
+	return ttl, false
 }
 
-func (c *Cache[T]) Close() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+func (c *Cache[T]) truncateTTL(ttl time.Time) time.Time {
+	if c.ttlPrecision <= 0 {
+		return ttl
+	}
 
-	if c.items == nil {
+	return ttl.Truncate(c.ttlPrecision)
+}
+
+func (c *Cache[T]) key(key string) string {
+	if c.keyFn == nil {
+		return key
+	}
+
+	return c.keyFn(key)
+}
+
+func (c *Cache[T]) hit() {
+	if c.statsOff {
 		return
 	}
 
-	if c.cancel != nil {
-		c.cancel()
-		c.cancel = nil
+	c.hits.Add(1)
+	c.window.Hit()
+}
+
+func (c *Cache[T]) miss() {
+	if c.statsOff {
+		return
 	}
 
-	c.items = nil
+	c.misses.Add(1)
+	c.window.Miss()
+}
+
+func (c *Cache[T]) expired(item *Item[T], now time.Time) bool {
+	return now.After(item.TTL) || c.idle(item, now)
+}
+
+func (c *Cache[T]) idle(item *Item[T], now time.Time) bool {
+	return c.maxIdle > 0 && now.Sub(time.Unix(0, item.lastAccess.Load())) > c.maxIdle
+}
+
+func (c *Cache[T]) touch(item *Item[T]) {
+	if c.maxIdle > 0 || (c.evictionPolicy() != PolicyFIFO && (c.maxWeight > 0 || c.maxEntries > 0)) {
+		item.lastAccess.Store(c.now().UnixNano())
+	}
 }
 
-func getSize[T any](val T) int64 {
-	if reflect.ValueOf(val).Kind() == reflect.Ptr {
-		ptrSize := int64(reflect.TypeOf(val).Size())
-		valSize := int64(reflect.TypeOf(val).Elem().Size())
-		return ptrSize + valSize
+func (c *Cache[T]) removable(item *Item[T], now time.Time) bool {
+	if c.idle(item, now) {
+		return true
+	}
+
+	if c.maxAge > 0 && !item.setAt.IsZero() && now.After(item.setAt.Add(c.maxAge).Add(c.stale)) {
+		return true
 	}
 
-	return int64(reflect.TypeOf(val).Size())
+	return now.After(item.TTL.Add(c.stale))
 }
 
 func zero[T any]() T {
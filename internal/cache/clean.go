@@ -5,51 +5,151 @@ import (
 	"time"
 )
 
+type CleanStrategy int
+
+const (
+	StrategyActive CleanStrategy = iota
+	StrategyLazy
+)
+
 func StartClean[T any](c *Cache[T], ctx context.Context, interval time.Duration) {
+	if c.strategy == StrategyLazy {
+		return
+	}
+
+	c.mu.Lock()
+	c.cleanInterval = interval
+	c.mu.Unlock()
+
+	startClean(c, ctx, interval)
+}
+
+func startClean[T any](c *Cache[T], ctx context.Context, interval time.Duration) {
+	c.cleaners.Add(1)
+	c.lastSweep.Store(time.Now().UnixNano())
+
 	go func() {
-	Loop:
+		defer c.cleaners.Add(-1)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
 		for {
 			select {
 			case <-ctx.Done():
-				break Loop
+				return
 
-			default:
-				time.Sleep(interval)
+			case <-ticker.C:
 				clean(c)
+				c.lastSweep.Store(time.Now().UnixNano())
 			}
 		}
 	}()
 }
 
+func CloseWhenDone[T any](c *Cache[T], ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+
+		c.mu.Lock()
+		var done <-chan struct{}
+		if c.ctx == ctx {
+			c.close()
+			done = c.stopEvictionWorker()
+		}
+		c.mu.Unlock()
+
+		if done != nil {
+			<-done
+		}
+	}()
+}
+
+func Clean[T any](c *Cache[T]) {
+	clean(c)
+}
+
+type candidate[T any] struct {
+	key   string
+	value *Item[T]
+}
+
 func clean[T any](c *Cache[T]) {
-	type tmp struct {
-		key   string
-		value *Item[T]
+	var expiredKeys []candidate[T]
+
+	c.mu.RLock()
+	bucketed := c.buckets != nil
+	if !bucketed {
+		now := c.now()
+		for k, v := range c.items {
+			if c.removable(v, now) {
+				expiredKeys = append(expiredKeys, candidate[T]{key: k, value: v})
+			}
+		}
 	}
+	c.mu.RUnlock()
 
-	var expiredKeys []*tmp
+	if !bucketed && len(expiredKeys) == 0 {
+		return
+	}
+
+	var evicted []KV[T]
 
-	for k, v := range c.items {
-		c.mu.RLock()
-		if time.Now().After(v.TTL) {
-			expiredKeys = append(expiredKeys, &tmp{key: k, value: v})
+	c.mu.Lock()
+	if bucketed {
+		if c.buckets == nil {
+			c.mu.Unlock()
+			return
 		}
-		c.mu.RUnlock()
+
+		expiredKeys = c.dueItems(c.now())
 	}
 
-	if len(expiredKeys) > 0 {
-		c.mu.Lock()
-		for _, k := range expiredKeys {
-			if c.onEvicted != nil {
-				c.onEvicted(k.key, k.value.Value)
-			}
+	var removed int
+	var freed int64
+
+	onBatch := c.onBatch
+	for _, k := range expiredKeys {
+		if !c.unlink(k.key, k.value) {
+			continue
+		}
 
-			delete(c.items, k.key)
+		c.stat.Evictions++
+		removed++
+		freed += k.value.size
 
-			c.stat.Evictions++
-			c.stat.SizeBytes -= int64(c.sizeof)
+		if c.logger != nil && (c.logEvery <= 1 || removed%c.logEvery == 1) {
+			c.logger.Debug("memo: entry evicted", "key", k.key, "reason", "expired")
 		}
-		c.mu.Unlock()
 
+		if onBatch != nil {
+			evicted = append(evicted, KV[T]{Key: k.key, Value: c.value(k.value)})
+			continue
+		}
+
+		c.guard("OnEvicted", func() { c.notifyEvicted(context.Background(), k.key, k.value) })
+	}
+	c.mu.Unlock()
+
+	if len(evicted) > 0 {
+		c.guard("OnEvictedBatch", func() { onBatch(evicted) })
 	}
+
+	if c.logger != nil && removed > 0 {
+		c.logger.Info("memo: sweep evicted entries", "entries", removed, "freed_bytes", freed)
+	}
+}
+
+func (c *Cache[T]) guard(callback string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.panicsRecovered.Add(1)
+
+			if c.logger != nil {
+				c.logger.Error("memo: callback panicked", "callback", callback, "panic", r)
+			}
+		}
+	}()
+
+	fn()
 }
@@ -0,0 +1,17 @@
+package cache
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *Cache[T]) now() time.Time {
+	return c.clock.Now()
+}
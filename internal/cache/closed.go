@@ -0,0 +1,22 @@
+package cache
+
+import "errors"
+
+type ClosedPolicy int
+
+const (
+	ClosedError ClosedPolicy = iota
+	ClosedPanic
+	ClosedIgnore
+)
+
+func (c *Cache[T]) closedErr() error {
+	switch c.closed {
+	case ClosedPanic:
+		panic("memo: write to closed cache")
+	case ClosedIgnore:
+		return nil
+	}
+
+	return errors.New("cache is closed")
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type entry[T any] struct {
+	Value T         `json:"value"`
+	TTL   time.Time `json:"ttl"`
+}
+
+func (c *Cache[T]) Save(w io.Writer) error {
+	if err := c.rlock(); err != nil {
+		return err
+	}
+	if c.items == nil {
+		c.mu.RUnlock()
+		return errors.New("cache is closed")
+	}
+
+	entries := c.snapshot()
+	c.mu.RUnlock()
+
+	bytes, err := c.codec.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bytes)
+	return err
+}
+
+func (c *Cache[T]) Restore(r io.Reader) error {
+	bytes, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var entries map[string]entry[T]
+	if err := c.codec.Unmarshal(bytes, &entries); err != nil {
+		return err
+	}
+
+	var onSet []func()
+	defer func() { runCallbacks(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	onSet, err = c.restore(entries)
+	return err
+}
+
+func (c *Cache[T]) snapshot() map[string]entry[T] {
+	entries := make(map[string]entry[T], len(c.items))
+
+	now := c.now()
+	for k, v := range c.items {
+		if c.expired(v, now) {
+			continue
+		}
+
+		entries[k] = entry[T]{Value: c.value(v), TTL: c.truncateTTL(v.TTL)}
+	}
+
+	return entries
+}
+
+func (c *Cache[T]) restore(entries map[string]entry[T]) ([]func(), error) {
+	if len(c.items) == 0 && len(entries) > 0 {
+		c.items = make(map[string]*Item[T], len(entries))
+	}
+
+	var onSet []func()
+	now := c.now()
+	for k, v := range entries {
+		if now.After(v.TTL) {
+			continue
+		}
+
+		item := c.newItemAt(v.Value, now, v.TTL)
+		if err := c.store(k, item); err != nil {
+			return onSet, err
+		}
+
+		if fn := c.setCallback(k, v.Value, item); fn != nil {
+			onSet = append(onSet, fn)
+		}
+	}
+
+	return onSet, nil
+}
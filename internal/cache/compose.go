@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+)
+
+func Key(parts ...string) string {
+	n := 0
+	for _, p := range parts {
+		n += len(p) + 4
+	}
+
+	var b strings.Builder
+	b.Grow(n)
+	for _, p := range parts {
+		b.WriteString(strconv.Itoa(len(p)))
+		b.WriteByte(':')
+		b.WriteString(p)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+type GzipCompressor struct {
+	Level int
+}
+
+func (g GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (c *Cache[T]) prepare(item *Item[T]) {
+	if c.compress != nil {
+		if packed, ok := c.compress(item.Value); ok {
+			item.Value = zero[T]()
+			item.packed = packed
+			item.compressed = true
+			item.size = int64(len(packed))
+			return
+		}
+	}
+
+	item.size = c.sizeOf(item.Value)
+}
+
+func (c *Cache[T]) value(item *Item[T]) T {
+	if !item.compressed {
+		return item.Value
+	}
+
+	val, err := c.decompress(item.packed)
+	if err != nil {
+		if c.logger != nil {
+			c.logger.Error("memo: decompress failed", "error", err)
+		}
+
+		return zero[T]()
+	}
+
+	return val
+}
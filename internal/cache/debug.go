@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+)
+
+func (c *Cache[T]) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Base(r.URL.Path) {
+		case "stats":
+			writeJSON(w, c.Stat())
+		case "keys":
+			var keys []string
+			if err := c.View(func(v ReadView[T]) { keys = v.Keys() }); err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			writeJSON(w, keys)
+		case "get":
+			c.serveGet(w, r.URL.Query().Get("key"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func (c *Cache[T]) serveGet(w http.ResponseWriter, key string) {
+	if key == "" {
+		http.Error(w, "key parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	var val T
+	var getErr error
+	if err := c.View(func(v ReadView[T]) { val, getErr = v.Get(key) }); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	if getErr != nil {
+		http.Error(w, getErr.Error(), http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("value of key %s can't be encoded as JSON: %v", key, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
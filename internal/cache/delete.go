@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const ctxCheckEvery = 1024
+
+func (c *Cache[T]) Delete(key string) error {
+	k := c.key(key)
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	if item, exists := c.items[k]; exists {
+		c.remove(k, item)
+	}
+
+	return nil
+}
+
+func (c *Cache[T]) DeleteMany(keys []string) (int, error) {
+	return c.DeleteManyWithContext(context.Background(), keys)
+}
+
+func (c *Cache[T]) DeleteManyWithContext(ctx context.Context, keys []string) (int, error) {
+	if err := c.lockContext(ctx); err != nil {
+		return 0, err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return 0, errors.New("cache is closed")
+	}
+
+	deleted := 0
+	for i, key := range keys {
+		if i%ctxCheckEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return deleted, err
+			}
+		}
+
+		k := c.key(key)
+		if item, exists := c.items[k]; exists && c.remove(k, item) {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (c *Cache[T]) Clear() (int, error) {
+	return c.ClearWithContext(context.Background())
+}
+
+func (c *Cache[T]) ClearWithContext(ctx context.Context) (int, error) {
+	if err := c.lockContext(ctx); err != nil {
+		return 0, err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return 0, errors.New("cache is closed")
+	}
+
+	deleted := 0
+	for k, item := range c.items {
+		if deleted%ctxCheckEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return deleted, err
+			}
+		}
+
+		if c.remove(k, item) {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (c *Cache[T]) EvictFunc(pred func(key string, value T, ttl time.Duration) bool) int {
+	type match struct {
+		key  string
+		item *Item[T]
+	}
+
+	var matches []match
+
+	if err := c.rlock(); err != nil {
+		return 0
+	}
+	now := c.now()
+	for k, v := range c.items {
+		if !c.expired(v, now) && pred(k, c.value(v), v.TTL.Sub(now)) {
+			matches = append(matches, match{key: k, item: v})
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(matches) == 0 {
+		return 0
+	}
+
+	if err := c.lock(); err != nil {
+		return 0
+	}
+	defer c.mu.Unlock()
+
+	evicted := 0
+	for _, m := range matches {
+		if c.remove(m.key, m.item) {
+			evicted++
+		}
+	}
+
+	return evicted
+}
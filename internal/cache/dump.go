@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+type EntryInfo[T any] struct {
+	Key       string
+	Value     T
+	ExpiresAt time.Time
+	TTL       time.Duration
+	SizeBytes int64
+}
+
+func (c *Cache[T]) Dump() []EntryInfo[T] {
+	if err := c.rlock(); err != nil {
+		return nil
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return nil
+	}
+
+	now := c.now()
+	entries := make([]EntryInfo[T], 0, len(c.items))
+	for k, v := range c.items {
+		if c.expired(v, now) {
+			continue
+		}
+
+		entries = append(entries, EntryInfo[T]{
+			Key:       k,
+			Value:     c.value(v),
+			ExpiresAt: v.TTL,
+			TTL:       v.TTL.Sub(now),
+			SizeBytes: v.size,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
@@ -0,0 +1,12 @@
+package cache
+
+import "errors"
+
+var ErrDuplicateKey = errors.New("duplicate key in batch")
+
+type DuplicatePolicy int
+
+const (
+	DuplicateLastWins DuplicatePolicy = iota
+	DuplicateError
+)
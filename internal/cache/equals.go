@@ -0,0 +1,23 @@
+package cache
+
+import "time"
+
+func (c *Cache[T]) unchanged(k string, value T, ttl time.Duration) bool {
+	if c.equals == nil {
+		return false
+	}
+
+	item, exists := c.items[k]
+	if !exists || c.expired(item, c.now()) || !c.equals(c.value(item), value) {
+		return false
+	}
+
+	ttl, _ = c.clampTTL(ttl)
+
+	refreshed := c.extend(item, ttl)
+	c.bucketRemove(k, item)
+	c.items[k] = refreshed
+	c.bucketAdd(k, refreshed)
+
+	return true
+}
@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+func (c *Cache[T]) store(k string, item *Item[T]) error {
+	if err := c.checkKey(k); err != nil {
+		return err
+	}
+
+	if item.weight < 0 {
+		return fmt.Errorf("weight %d of key %s is negative", item.weight, k)
+	}
+
+	if c.maxWeight > 0 && item.weight > c.maxWeight {
+		return fmt.Errorf("weight %d of key %s exceeds max weight %d", item.weight, k, c.maxWeight)
+	}
+
+	c.prepare(item)
+
+	old, exists := c.items[k]
+	if exists {
+		item.pinned = old.pinned
+		c.unlink(k, old)
+	}
+	c.link(k, item)
+
+	if err := c.enforceCapacity(k); err != nil {
+		c.unlink(k, item)
+		if exists {
+			c.link(k, old)
+		}
+		return err
+	}
+	c.checkGrowth()
+
+	return nil
+}
+
+func (c *Cache[T]) link(k string, item *Item[T]) {
+	c.items[k] = item
+	c.indexAdd(k, item)
+	c.bucketAdd(k, item)
+	c.keyBytes += int64(len(k))
+	c.weight += item.weight
+	c.stat.SizeBytes += item.size
+}
+
+func (c *Cache[T]) enforceCapacity(keep string) error {
+	if !c.overCapacity() {
+		return nil
+	}
+
+	now := c.now()
+	if c.sampleSize <= 0 {
+		c.evictExpired(keep, now)
+	}
+
+	for c.overCapacity() {
+		k, item := c.victim(keep, now)
+		if item == nil {
+			return ErrAllPinned
+		}
+
+		if c.expired(item, now) {
+			c.evict(k, item)
+			continue
+		}
+
+		c.evictForCapacity(k, item)
+	}
+
+	return nil
+}
+
+func (c *Cache[T]) overCapacity() bool {
+	return (c.maxWeight > 0 && c.weight > c.maxWeight) ||
+		(c.maxEntries > 0 && len(c.items) > c.maxEntries)
+}
+
+func (c *Cache[T]) evictExpired(keep string, now time.Time) {
+	for k, v := range c.items {
+		if k != keep && c.expired(v, now) {
+			c.evict(k, v)
+		}
+	}
+}
+
+func (c *Cache[T]) victim(keep string, now time.Time) (string, *Item[T]) {
+	var victimKey string
+	var victim *Item[T]
+	var victimExpired bool
+
+	sampled := 0
+	for k, v := range c.items {
+		if k == keep {
+			continue
+		}
+
+		expired := c.expired(v, now)
+		if v.pinned && !expired {
+			continue
+		}
+
+		if victim == nil || (expired && !victimExpired) ||
+			(expired == victimExpired && c.evictBefore(v, victim)) {
+			victimKey, victim, victimExpired = k, v, expired
+		}
+
+		sampled++
+		if c.sampleSize > 0 && sampled >= c.sampleSize {
+			break
+		}
+	}
+
+	return victimKey, victim
+}
+
+func (c *Cache[T]) evictForCapacity(k string, item *Item[T]) {
+	if c.onCapacity == nil {
+		if c.remove(k, item) {
+			c.stat.Evictions++
+
+			if c.logger != nil {
+				c.logger.Debug("memo: entry evicted", "key", k, "reason", "capacity")
+			}
+		}
+		return
+	}
+
+	if !c.unlink(k, item) {
+		return
+	}
+
+	c.stat.Evictions++
+	if c.logger != nil {
+		c.logger.Debug("memo: entry evicted", "key", k, "reason", "capacity")
+	}
+
+	if err := c.onCapacity(k, c.value(item)); err != nil {
+		c.stat.CapacityEvictErrors++
+
+		if c.logger != nil {
+			c.logger.Warn("memo: capacity eviction callback failed", "key", k, "error", err)
+		}
+	}
+}
+
+func (c *Cache[T]) TrimTo(targetBytes int64) int {
+	if err := c.lock(); err != nil {
+		return 0
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return 0
+	}
+
+	trimmed := 0
+	now := c.now()
+	for c.stat.SizeBytes > targetBytes {
+		k, item := c.victim("", now)
+		if item == nil {
+			break
+		}
+
+		if c.expired(item, now) {
+			c.evict(k, item)
+		} else {
+			c.evictForCapacity(k, item)
+		}
+		trimmed++
+	}
+
+	return trimmed
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type flight[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+func (c *Cache[T]) GetOrSet(key string, ttl time.Duration, fn func() (T, error)) (T, error) {
+	if val, err := c.Get(key); err == nil {
+		return val, nil
+	}
+
+	k := c.key(key)
+
+	c.flightMu.Lock()
+	if f, exists := c.flights[k]; exists {
+		c.flightMu.Unlock()
+		<-f.done
+		return c.copyValue(f.val), f.err
+	}
+
+	if c.flights == nil {
+		c.flights = make(map[string]*flight[T])
+	}
+
+	f := &flight[T]{done: make(chan struct{}), err: errors.New("GetOrSet function panicked")}
+	c.flights[k] = f
+	c.flightMu.Unlock()
+
+	defer func() {
+		c.flightMu.Lock()
+		delete(c.flights, k)
+		c.flightMu.Unlock()
+		close(f.done)
+	}()
+
+	if val, ok := c.lookup(key); ok {
+		f.val, f.err = val, nil
+		return val, nil
+	}
+
+	val, err := fn()
+	if err == nil {
+		err = c.Set(key, val, ttl)
+	}
+
+	if err != nil {
+		f.val, f.err = zero[T](), err
+		return zero[T](), err
+	}
+
+	f.val, f.err = val, nil
+	return c.copyValue(val), nil
+}
+
+// lookup returns the live value of key without counting a hit or a miss,
+// GetOrSet uses it to check the key again once it owns the flight.
+func (c *Cache[T]) lookup(key string) (T, bool) {
+	k := c.key(key)
+
+	if err := c.rlock(); err != nil {
+		return zero[T](), false
+	}
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[k]
+	if !exists || c.expired(item, c.now()) {
+		return zero[T](), false
+	}
+
+	c.touch(item)
+	return c.copyValue(c.value(item)), true
+}
+
+func (c *Cache[T]) GetOrLoadMany(keys []string, ttl time.Duration, loader func(missing []string) (map[string]T, error)) (map[string]T, error) {
+	values, found := c.GetOrdered(keys)
+
+	result := make(map[string]T, len(keys))
+	var missing []string
+	seen := make(map[string]struct{})
+	for i, key := range keys {
+		if found[i] {
+			result[key] = values[i]
+			continue
+		}
+
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		missing = append(missing, key)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	loaded, err := loader(missing)
+	if err != nil {
+		return result, err
+	}
+
+	items := make(map[string]T, len(missing))
+	for _, key := range missing {
+		if val, ok := loaded[key]; ok {
+			items[key] = val
+		}
+	}
+
+	errs := c.SetMany(items, ttl)
+	for key, val := range items {
+		result[key] = c.copyValue(val)
+	}
+
+	if len(errs) == 0 {
+		return result, nil
+	}
+
+	joined := make([]error, 0, len(errs))
+	for key, err := range errs {
+		joined = append(joined, fmt.Errorf("%s: %w", key, err))
+	}
+
+	return result, errors.Join(joined...)
+}
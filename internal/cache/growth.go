@@ -0,0 +1,30 @@
+package cache
+
+type growthAlert struct {
+	threshold int
+	fn        func(count int)
+	fired     bool
+}
+
+// checkGrowth fires the alert when the live count goes above the threshold.
+// The map size is checked first, it is never below the live count, so the
+// entries are only counted when the alert may fire.
+func (c *Cache[T]) checkGrowth() {
+	if c.growth.fn == nil || c.growth.fired || len(c.items) <= c.growth.threshold {
+		return
+	}
+
+	count := c.live()
+	if count <= c.growth.threshold {
+		return
+	}
+
+	c.growth.fired = true
+	c.guard("GrowthAlert", func() { c.growth.fn(count) })
+}
+
+func (c *Cache[T]) rearmGrowth() {
+	if c.growth.fired && len(c.items) <= c.growth.threshold {
+		c.growth.fired = false
+	}
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const staleSweeps = 3
+
+func (c *Cache[T]) Healthy() error {
+	if err := c.rlock(); err != nil {
+		return err
+	}
+	closed := c.items == nil
+	interval := c.cleanInterval
+	c.mu.RUnlock()
+
+	if closed {
+		return errors.New("cache is closed")
+	}
+
+	if c.strategy == StrategyActive && c.cleaners.Load() == 0 {
+		return errors.New("cleaner is not running")
+	}
+
+	since := time.Since(time.Unix(0, c.lastSweep.Load()))
+	if c.strategy == StrategyActive && interval > 0 && since > interval*staleSweeps {
+		return fmt.Errorf("cleaner has not finished a sweep for %s, interval is %s", since.Round(time.Millisecond), interval)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+func (c *Cache[T]) TTLHistogram(buckets []time.Duration) map[time.Duration]int {
+	bounds := append([]time.Duration(nil), buckets...)
+	sort.Slice(bounds, func(i, j int) bool {
+		return bounds[i] < bounds[j]
+	})
+
+	hist := make(map[time.Duration]int, len(bounds))
+	for _, b := range bounds {
+		hist[b] = 0
+	}
+
+	if err := c.rlock(); err != nil {
+		return nil
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil || len(bounds) == 0 {
+		return hist
+	}
+
+	now := c.now()
+	for _, v := range c.items {
+		if c.expired(v, now) {
+			continue
+		}
+
+		remaining := v.TTL.Sub(now)
+		i := sort.Search(len(bounds), func(i int) bool {
+			return bounds[i] >= remaining
+		})
+		if i < len(bounds) {
+			hist[bounds[i]]++
+		}
+	}
+
+	return hist
+}
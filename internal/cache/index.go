@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+type index[T any] struct {
+	extract func(T) string
+	keys    map[string]map[string]struct{}
+}
+
+func (c *Cache[T]) AddIndex(name string, extractor func(T) string) error {
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	if _, exists := c.indexes[name]; exists {
+		return fmt.Errorf("index %s already exists", name)
+	}
+
+	if c.indexes == nil {
+		c.indexes = make(map[string]*index[T])
+	}
+
+	idx := &index[T]{extract: extractor, keys: make(map[string]map[string]struct{})}
+	for k, v := range c.items {
+		idx.add(k, c.value(v))
+	}
+	c.indexes[name] = idx
+
+	return nil
+}
+
+func (c *Cache[T]) GetByIndex(name, value string) ([]T, error) {
+	if err := c.rlock(); err != nil {
+		return nil, err
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return nil, errors.New("cache is closed")
+	}
+
+	idx, exists := c.indexes[name]
+	if !exists {
+		return nil, fmt.Errorf("index %s does not exists", name)
+	}
+
+	keys := make([]string, 0, len(idx.keys[value]))
+	for k := range idx.keys[value] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := c.now()
+	values := make([]T, 0, len(keys))
+	for _, k := range keys {
+		if item, exists := c.items[k]; exists && !c.expired(item, now) {
+			values = append(values, c.copyValue(c.value(item)))
+		}
+	}
+
+	return values, nil
+}
+
+func (c *Cache[T]) indexAdd(k string, item *Item[T]) {
+	if len(c.indexes) == 0 {
+		return
+	}
+
+	value := c.value(item)
+	for _, idx := range c.indexes {
+		idx.add(k, value)
+	}
+}
+
+func (c *Cache[T]) indexRemove(k string, item *Item[T]) {
+	if len(c.indexes) == 0 {
+		return
+	}
+
+	value := c.value(item)
+	for _, idx := range c.indexes {
+		idx.remove(k, value)
+	}
+}
+
+func (c *Cache[T]) resetIndexes() {
+	for _, idx := range c.indexes {
+		idx.keys = make(map[string]map[string]struct{})
+	}
+}
+
+func (idx *index[T]) add(k string, value T) {
+	v := idx.extract(value)
+
+	keys, exists := idx.keys[v]
+	if !exists {
+		keys = make(map[string]struct{})
+		idx.keys[v] = keys
+	}
+	keys[k] = struct{}{}
+}
+
+func (idx *index[T]) remove(k string, value T) {
+	v := idx.extract(value)
+
+	keys := idx.keys[v]
+	delete(keys, k)
+	if len(keys) == 0 {
+		delete(idx.keys, v)
+	}
+}
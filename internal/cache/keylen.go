@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrKeyTooLong = errors.New("key is too long")
+
+func (c *Cache[T]) checkKey(k string) error {
+	if c.maxKeyLen > 0 && len(k) > c.maxKeyLen {
+		c.stat.RejectedSets++
+		return fmt.Errorf("%w: %d bytes, max %d", ErrKeyTooLong, len(k), c.maxKeyLen)
+	}
+
+	return nil
+}
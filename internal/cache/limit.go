@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrLoadBusy = errors.New("too many concurrent loads")
+
+type BusyPolicy int
+
+const (
+	BusyWait BusyPolicy = iota
+	BusyError
+)
+
+func (c *Cache[T]) acquireLoad(ctx context.Context) error {
+	if c.loadSem != nil {
+		if c.busy == BusyError {
+			select {
+			case c.loadSem <- struct{}{}:
+			default:
+				return ErrLoadBusy
+			}
+		} else {
+			select {
+			case c.loadSem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	c.loadsInFlight.Add(1)
+	return nil
+}
+
+func (c *Cache[T]) releaseLoad() {
+	c.loadsInFlight.Add(-1)
+
+	if c.loadSem != nil {
+		<-c.loadSem
+	}
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrLoaderCircuitOpen = errors.New("loader circuit is open")
+
+const refreshQueueSize = 1024
+
+type Loader[T any] func(ctx context.Context, key string) (T, error)
+
+type TTLLoader[T any] func(ctx context.Context, key string) (T, time.Duration, error)
+
+func (c *Cache[T]) Load(ctx context.Context, key string) (T, error) {
+	if c.loader == nil {
+		return zero[T](), errors.New("loader is not configured")
+	}
+
+	if val, err := c.GetWithContext(ctx, key); err == nil {
+		return val, nil
+	}
+
+	if !c.breaker.allow(c.now()) {
+		if val, ok := c.peek(key); ok {
+			return val, nil
+		}
+
+		return zero[T](), ErrLoaderCircuitOpen
+	}
+
+	val, ttl, err := c.callLoader(ctx, key)
+	if err != nil {
+		if c.logger != nil {
+			c.logger.Warn("memo: load failed", "key", key, "error", err)
+		}
+
+		return zero[T](), err
+	}
+
+	ttl, ok := c.loadedTTL(ttl)
+	if !ok {
+		return val, nil
+	}
+
+	if err := c.SetWithContext(ctx, key, val, ttl); err != nil {
+		return zero[T](), err
+	}
+
+	return c.copyValue(val), nil
+}
+
+func (c *Cache[T]) callLoader(ctx context.Context, key string) (T, time.Duration, error) {
+	for attempt := 1; ; attempt++ {
+		if err := c.acquireLoad(ctx); err != nil {
+			return zero[T](), 0, err
+		}
+
+		val, ttl, err := c.loader(ctx, key)
+		c.releaseLoad()
+
+		if err == nil || attempt >= c.retryAttempts || ctx.Err() != nil {
+			c.breaker.record(err, c.now(), c.logger)
+			return val, ttl, err
+		}
+
+		if c.logger != nil {
+			c.logger.Debug("memo: load failed, retrying", "key", key, "attempt", attempt, "error", err)
+		}
+
+		timer := time.NewTimer(c.retryDelay << (attempt - 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.breaker.record(err, c.now(), c.logger)
+			return zero[T](), 0, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Cache[T]) Refresh(key string) error {
+	if c.loader == nil {
+		return errors.New("loader is not configured")
+	}
+
+	if err := c.rlock(); err != nil {
+		return err
+	}
+	closed := c.items == nil
+	c.mu.RUnlock()
+
+	if closed {
+		return errors.New("cache is closed")
+	}
+
+	return c.refresh(key)
+}
+
+func (c *Cache[T]) refresh(key string) error {
+	// refreshes coalesce on the transformed key, the loader still gets the
+	// key as the caller spelled it.
+	k := c.key(key)
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if _, exists := c.refreshing[k]; exists {
+		if c.logger != nil {
+			c.logger.Debug("memo: refresh coalesced", "key", key)
+		}
+
+		return nil
+	}
+
+	if c.refreshQueue == nil {
+		c.refreshing[k] = struct{}{}
+		go c.runRefresh(key)
+		return nil
+	}
+
+	select {
+	case c.refreshQueue <- key:
+		c.refreshing[k] = struct{}{}
+		c.refreshQueued.Add(1)
+		return nil
+	default:
+		if c.logger != nil {
+			c.logger.Warn("memo: refresh queue is full", "key", key)
+		}
+
+		return errors.New("refresh queue is full")
+	}
+}
+
+func (c *Cache[T]) startRefreshWorkers(n int) {
+	queue := make(chan string, refreshQueueSize)
+	c.refreshQueue = queue
+
+	ctx := c.ctx
+	for i := 0; i < n; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case key := <-queue:
+					c.refreshQueued.Add(-1)
+					c.runRefresh(key)
+				}
+			}
+		}()
+	}
+}
+
+func (c *Cache[T]) runRefresh(key string) {
+	c.refreshInFlight.Add(1)
+	defer c.refreshInFlight.Add(-1)
+
+	defer func() {
+		c.refreshMu.Lock()
+		delete(c.refreshing, c.key(key))
+		c.refreshMu.Unlock()
+	}()
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !c.breaker.allow(c.now()) {
+		if c.logger != nil {
+			c.logger.Debug("memo: refresh skipped, loader circuit is open", "key", key)
+		}
+
+		return
+	}
+
+	val, ttl, err := c.callLoader(ctx, key)
+	if err != nil {
+		if c.logger != nil {
+			c.logger.Warn("memo: refresh failed", "key", key, "error", err)
+		}
+
+		return
+	}
+
+	if ttl, ok := c.loadedTTL(ttl); ok {
+		c.Set(key, val, ttl)
+	}
+}
+
+func (c *Cache[T]) loadedTTL(ttl time.Duration) (time.Duration, bool) {
+	if ttl == 0 {
+		ttl = c.loaderTTL
+	}
+
+	return ttl, ttl > 0
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const maxLockBackoff = time.Millisecond
+
+var ErrTimeout = errors.New("timed out waiting for the cache lock")
+
+func (c *Cache[T]) lock() error {
+	if c.lockStats.sample() {
+		start := time.Now()
+		defer func() { c.lockStats.record(time.Since(start)) }()
+	}
+
+	if c.defaultTimeout <= 0 {
+		c.mu.Lock()
+		return nil
+	}
+
+	if c.mu.TryLock() {
+		return nil
+	}
+
+	return c.withTimeout(c.lockContext)
+}
+
+func (c *Cache[T]) rlock() error {
+	if c.lockStats.sample() {
+		start := time.Now()
+		defer func() { c.lockStats.record(time.Since(start)) }()
+	}
+
+	if c.defaultTimeout <= 0 {
+		c.mu.RLock()
+		return nil
+	}
+
+	if c.mu.TryRLock() {
+		return nil
+	}
+
+	return c.withTimeout(c.rlockContext)
+}
+
+func (c *Cache[T]) withTimeout(lock func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.defaultTimeout)
+	defer cancel()
+
+	if err := lock(ctx); err != nil {
+		return ErrTimeout
+	}
+
+	return nil
+}
+
+// lockContext polls TryLock so it can give up when ctx is done. A steady
+// stream of readers can starve that loop, so a context that can't be
+// cancelled takes the blocking lock instead.
+func (c *Cache[T]) lockContext(ctx context.Context) error {
+	if ctx.Done() == nil {
+		return c.lock()
+	}
+
+	return acquire(ctx, c.mu.TryLock, c.mu.Unlock)
+}
+
+func (c *Cache[T]) rlockContext(ctx context.Context) error {
+	if ctx.Done() == nil {
+		return c.rlock()
+	}
+
+	return acquire(ctx, c.mu.TryRLock, c.mu.RUnlock)
+}
+
+func acquire(ctx context.Context, try func() bool, release func()) error {
+	backoff := time.Microsecond
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if try() {
+			break
+		}
+
+		time.Sleep(backoff)
+		if backoff < maxLockBackoff {
+			backoff *= 2
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		release()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+type lockStats struct {
+	every   uint64
+	ticks   atomic.Uint64
+	samples atomic.Uint64
+	total   atomic.Int64
+	buckets [65]atomic.Uint64
+}
+
+func (l *lockStats) sample() bool {
+	return l.every > 0 && l.ticks.Add(1)%l.every == 0
+}
+
+func (l *lockStats) record(wait time.Duration) {
+	l.samples.Add(1)
+	l.total.Add(int64(wait))
+	l.buckets[bits.Len64(uint64(wait))].Add(1)
+}
+
+func (l *lockStats) reset() {
+	l.ticks.Store(0)
+	l.samples.Store(0)
+	l.total.Store(0)
+	for i := range l.buckets {
+		l.buckets[i].Store(0)
+	}
+}
+
+func (l *lockStats) avg() time.Duration {
+	n := l.samples.Load()
+	if n == 0 {
+		return 0
+	}
+
+	return time.Duration(l.total.Load() / int64(n))
+}
+
+func (l *lockStats) p99() time.Duration {
+	n := l.samples.Load()
+	if n == 0 {
+		return 0
+	}
+
+	want := (n*99 + 99) / 100
+	var seen uint64
+	for i := range l.buckets {
+		seen += l.buckets[i].Load()
+		if seen >= want {
+			return time.Duration(uint64(1)<<i - 1)
+		}
+	}
+
+	return time.Duration(1<<63 - 1)
+}
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+func (c *Cache[T]) GetManyAndRefresh(keys []string, ttl time.Duration) (map[string]T, []string) {
+	found := make(map[string]T, len(keys))
+	var missing []string
+
+	if err := c.lock(); err != nil {
+		return found, keys
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return found, keys
+	}
+
+	now := c.now()
+	for _, key := range keys {
+		k := c.key(key)
+
+		item, exists := c.items[k]
+		if !exists || c.expired(item, now) {
+			c.miss()
+			missing = append(missing, key)
+			continue
+		}
+
+		c.hit()
+
+		refreshed := c.extend(item, ttl)
+		c.bucketRemove(k, item)
+		c.items[k] = refreshed
+		c.bucketAdd(k, refreshed)
+		found[key] = c.copyValue(c.value(refreshed))
+	}
+
+	return found, missing
+}
+
+func (c *Cache[T]) extend(item *Item[T], ttl time.Duration) *Item[T] {
+	now := c.now()
+
+	extended := &Item[T]{
+		Value:   item.Value,
+		TTL:     now.Add(ttl),
+		setAt:   item.setAt,
+		version: item.version,
+		weight:  item.weight,
+		size:    item.size,
+
+		packed:     item.packed,
+		compressed: item.compressed,
+		pinned:     item.pinned,
+	}
+	extended.lastAccess.Store(now.UnixNano())
+	extended.TTL = c.truncateTTL(c.capAge(extended.TTL, item.setAt))
+
+	return extended
+}
+
+func (c *Cache[T]) RefreshMatching(pred func(key string) bool, ttl time.Duration) int {
+	if err := c.lock(); err != nil {
+		return 0
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return 0
+	}
+
+	refreshed := 0
+	now := c.now()
+	for k, item := range c.items {
+		if c.expired(item, now) || !pred(k) {
+			continue
+		}
+
+		extended := c.extend(item, ttl)
+		extended.lastAccess.Store(item.lastAccess.Load())
+		c.bucketRemove(k, item)
+		c.items[k] = extended
+		c.bucketAdd(k, extended)
+		refreshed++
+	}
+
+	return refreshed
+}
+
+func (c *Cache[T]) GetOrdered(keys []string) ([]T, []bool) {
+	values := make([]T, len(keys))
+	found := make([]bool, len(keys))
+
+	if err := c.rlock(); err != nil {
+		return values, found
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return values, found
+	}
+
+	now := c.now()
+	for i, key := range keys {
+		item, exists := c.items[c.key(key)]
+		if !exists || c.expired(item, now) {
+			c.miss()
+			continue
+		}
+
+		c.hit()
+		c.touch(item)
+		values[i] = c.copyValue(c.value(item))
+		found[i] = true
+	}
+
+	return values, found
+}
+
+func (c *Cache[T]) SetMany(items map[string]T, ttl time.Duration) map[string]error {
+	var onSet []func()
+	defer func() {
+		for _, fn := range onSet {
+			fn()
+		}
+	}()
+
+	if err := c.lock(); err != nil {
+		return failAll(items, err)
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		if err := c.closedErr(); err != nil {
+			return failAll(items, err)
+		}
+		return nil
+	}
+
+	var seen map[string]struct{}
+	if c.duplicates == DuplicateError {
+		seen = make(map[string]struct{}, len(items))
+	}
+
+	errs := make(map[string]error)
+	for key, value := range items {
+		k := c.key(key)
+
+		if _, dup := seen[k]; dup {
+			errs[key] = fmt.Errorf("%w: %s", ErrDuplicateKey, key)
+			continue
+		}
+
+		item := c.newItem(value, ttl)
+		if err := c.store(k, item); err != nil {
+			errs[key] = err
+			continue
+		}
+
+		if seen != nil {
+			seen[k] = struct{}{}
+		}
+
+		if fn := c.setCallback(k, value, item); fn != nil {
+			onSet = append(onSet, fn)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func failAll[T any](items map[string]T, err error) map[string]error {
+	errs := make(map[string]error, len(items))
+	for key := range items {
+		errs[key] = err
+	}
+
+	return errs
+}
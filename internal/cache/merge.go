@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type MergeStrategy int
+
+const (
+	Overwrite MergeStrategy = iota
+	KeepExisting
+	KeepNewer
+)
+
+func (c *Cache[T]) MergeJSON(bytes []byte, strategy MergeStrategy) error {
+	if strategy < Overwrite || strategy > KeepNewer {
+		return fmt.Errorf("unknown merge strategy %d", strategy)
+	}
+
+	var temp map[string]struct {
+		Value T         `json:"value"`
+		TTL   time.Time `json:"ttl"`
+	}
+
+	if err := json.Unmarshal(bytes, &temp); err != nil {
+		return err
+	}
+
+	var onSet []func()
+	defer func() { runCallbacks(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	now := c.now()
+	for k, v := range temp {
+		if now.After(v.TTL) {
+			continue
+		}
+
+		if old, exists := c.items[k]; exists && !c.expired(old, now) {
+			if strategy == KeepExisting {
+				continue
+			}
+
+			if strategy == KeepNewer && !v.TTL.After(old.TTL) {
+				continue
+			}
+		}
+
+		item := c.newItemAt(v.Value, now, v.TTL)
+		if err := c.store(k, item); err != nil {
+			return err
+		}
+
+		if fn := c.setCallback(k, v.Value, item); fn != nil {
+			onSet = append(onSet, fn)
+		}
+	}
+
+	return nil
+}
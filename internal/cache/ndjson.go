@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const ndjsonBatch = 1024
+
+type record[T any] struct {
+	Key   string    `json:"key"`
+	Value T         `json:"value"`
+	TTL   time.Time `json:"ttl"`
+}
+
+func (c *Cache[T]) MarshalNDJSON(w io.Writer) error {
+	if err := c.rlock(); err != nil {
+		return err
+	}
+	if c.items == nil {
+		c.mu.RUnlock()
+		return errors.New("cache is closed")
+	}
+
+	entries := c.snapshot()
+	c.mu.RUnlock()
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	for k, v := range entries {
+		if err := enc.Encode(record[T]{Key: k, Value: v.Value, TTL: v.TTL}); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
+
+func (c *Cache[T]) UnmarshalNDJSON(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	batch := make(map[string]entry[T], ndjsonBatch)
+
+	var seen map[string]struct{}
+	if c.duplicates == DuplicateError {
+		seen = make(map[string]struct{})
+	}
+
+	for n := 1; ; n++ {
+		var l record[T]
+		err := dec.Decode(&l)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if rerr := c.restoreBatch(batch); rerr != nil {
+				return rerr
+			}
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+
+		if seen != nil {
+			if _, dup := seen[l.Key]; dup {
+				if err := c.restoreBatch(batch); err != nil {
+					return err
+				}
+				return fmt.Errorf("line %d: %w: %s", n, ErrDuplicateKey, l.Key)
+			}
+			seen[l.Key] = struct{}{}
+		}
+
+		batch[l.Key] = entry[T]{Value: l.Value, TTL: l.TTL}
+		if len(batch) >= ndjsonBatch {
+			if err := c.restoreBatch(batch); err != nil {
+				return err
+			}
+			clear(batch)
+		}
+	}
+
+	return c.restoreBatch(batch)
+}
+
+func (c *Cache[T]) restoreBatch(entries map[string]entry[T]) error {
+	var onSet []func()
+	defer func() { runCallbacks(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	var err error
+	onSet, err = c.restore(entries)
+	return err
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+func (c *Cache[T]) OnSet(fn func(key string, value T, ttl time.Duration)) error {
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	c.onSet = fn
+	return nil
+}
+
+func (c *Cache[T]) setCallback(k string, value T, item *Item[T]) func() {
+	onSet := c.onSet
+	if onSet == nil {
+		return nil
+	}
+
+	ttl := item.TTL.Sub(item.setAt)
+	return func() { onSet(k, value, ttl) }
+}
+
+func runCallback(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
+
+func runCallbacks(fns []func()) {
+	for _, fn := range fns {
+		fn()
+	}
+}
@@ -0,0 +1,313 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/crewcrew23/memo/internal/stat"
+)
+
+type Option[T any] func(*Cache[T])
+
+func WithDeepCopyOnGet[T any]() Option[T] {
+	return func(c *Cache[T]) {
+		c.deepCopy = true
+	}
+}
+
+func WithCloneOnGet[T any](fn func(T) T) Option[T] {
+	return func(c *Cache[T]) {
+		c.clone = fn
+	}
+}
+
+func WithValueEquals[T any](fn func(a, b T) bool) Option[T] {
+	return func(c *Cache[T]) {
+		c.equals = fn
+	}
+}
+
+func WithTTLPrecision[T any](d time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.ttlPrecision = d
+	}
+}
+
+func WithStaleWindow[T any](d time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.stale = d
+	}
+}
+
+func WithHitRateWindow[T any](d time.Duration, buckets int) Option[T] {
+	return func(c *Cache[T]) {
+		c.window = stat.NewWindow(d, buckets)
+	}
+}
+
+func WithKeyTransform[T any](fn func(string) string) Option[T] {
+	return func(c *Cache[T]) {
+		c.keyFn = fn
+	}
+}
+
+func WithMaxAge[T any](d time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.maxAge = d
+	}
+}
+
+func WithInitialCapacity[T any](n int) Option[T] {
+	return func(c *Cache[T]) {
+		if n < 0 {
+			n = 0
+		}
+
+		c.items = make(map[string]*Item[T], n)
+	}
+}
+
+func WithPersistOnClose[T any](path string) Option[T] {
+	return func(c *Cache[T]) {
+		c.persistPath = path
+	}
+}
+
+func WithAutoPersist[T any](path string, every time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.autoPersistPath = path
+		c.autoPersistEvery = every
+	}
+}
+
+func WithRestoreOnStart[T any](path string) Option[T] {
+	return func(c *Cache[T]) {
+		c.restorePath = path
+	}
+}
+
+func WithLoader[T any](fn Loader[T], ttl time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.loader = func(ctx context.Context, key string) (T, time.Duration, error) {
+			val, err := fn(ctx, key)
+			return val, ttl, err
+		}
+		c.loaderTTL = ttl
+	}
+}
+
+func WithTTLLoader[T any](fn TTLLoader[T], zeroTTL time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.loader = fn
+		c.loaderTTL = zeroTTL
+	}
+}
+
+func WithRefreshWorkers[T any](n int) Option[T] {
+	return func(c *Cache[T]) {
+		c.refreshWorkers = n
+	}
+}
+
+func WithClosedPolicy[T any](policy ClosedPolicy) Option[T] {
+	return func(c *Cache[T]) {
+		c.closed = policy
+	}
+}
+
+func WithMaxWeight[T any](max int64) Option[T] {
+	return func(c *Cache[T]) {
+		c.maxWeight = max
+	}
+}
+
+func WithMaxIdle[T any](d time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.maxIdle = d
+	}
+}
+
+func WithShardCount[T any](n int) Option[T] {
+	return func(c *Cache[T]) {
+		c.shardCount = n
+	}
+}
+
+func WithShardHasher[T any](fn func(string) uint64) Option[T] {
+	return func(c *Cache[T]) {
+		c.shardHasher = fn
+	}
+}
+
+func WithClock[T any](clock Clock) Option[T] {
+	return func(c *Cache[T]) {
+		c.clock = clock
+	}
+}
+
+func WithLogger[T any](logger *slog.Logger) Option[T] {
+	return func(c *Cache[T]) {
+		c.logger = logger
+	}
+}
+
+func WithAsyncEviction[T any](bufferSize int, policy QueuePolicy) Option[T] {
+	return func(c *Cache[T]) {
+		c.evictBuffer = bufferSize
+		c.evictPolicy = policy
+	}
+}
+
+func WithEvictionLogSampling[T any](every int) Option[T] {
+	return func(c *Cache[T]) {
+		c.logEvery = every
+	}
+}
+
+func WithEvictionSampleSize[T any](k int) Option[T] {
+	return func(c *Cache[T]) {
+		c.sampleSize = k
+	}
+}
+
+func WithTTLBounds[T any](min, max time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.minTTL = min
+		c.maxTTL = max
+	}
+}
+
+func WithLoaderCircuitBreaker[T any](threshold int, window, cooldown time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.breaker.threshold = threshold
+		c.breaker.window = window
+		c.breaker.cooldown = cooldown
+	}
+}
+
+func WithCodec[T any](codec Codec) Option[T] {
+	return func(c *Cache[T]) {
+		c.codec = codec
+	}
+}
+
+func WithMaxEntries[T any](n int) Option[T] {
+	return func(c *Cache[T]) {
+		c.maxEntries = n
+	}
+}
+
+func WithGrowthAlert[T any](threshold int, fn func(count int)) Option[T] {
+	return func(c *Cache[T]) {
+		c.growth = growthAlert{threshold: threshold, fn: fn}
+	}
+}
+
+func WithDuplicateKeys[T any](policy DuplicatePolicy) Option[T] {
+	return func(c *Cache[T]) {
+		c.duplicates = policy
+	}
+}
+
+func WithMaxKeyLength[T any](n int) Option[T] {
+	return func(c *Cache[T]) {
+		c.maxKeyLen = n
+	}
+}
+
+func WithEvictionPolicy[T any](policy EvictionPolicy) Option[T] {
+	return func(c *Cache[T]) {
+		c.policy.Store(int32(policy))
+	}
+}
+
+func WithSizer[T any](fn func(T) int64) Option[T] {
+	return func(c *Cache[T]) {
+		c.sizer = fn
+	}
+}
+
+func WithMaxConcurrentLoads[T any](n int, policy BusyPolicy) Option[T] {
+	return func(c *Cache[T]) {
+		if n > 0 {
+			c.loadSem = make(chan struct{}, n)
+		}
+		c.busy = policy
+	}
+}
+
+func WithCompression[T ~[]byte | ~string](comp Compressor, threshold int) Option[T] {
+	return func(c *Cache[T]) {
+		c.compress = func(val T) ([]byte, bool) {
+			if len(val) < threshold {
+				return nil, false
+			}
+
+			packed, err := comp.Compress([]byte(val))
+			if err != nil || len(packed) >= len(val) {
+				return nil, false
+			}
+
+			return packed, true
+		}
+
+		c.decompress = func(packed []byte) (T, error) {
+			data, err := comp.Decompress(packed)
+			if err != nil {
+				return zero[T](), err
+			}
+
+			return T(data), nil
+		}
+	}
+}
+
+func WithDrainOnClose[T any](fn func(key string, value T)) Option[T] {
+	return func(c *Cache[T]) {
+		c.drain = fn
+	}
+}
+
+func WithLoaderRetry[T any](maxAttempts int, baseDelay time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.retryAttempts = maxAttempts
+		c.retryDelay = baseDelay
+	}
+}
+
+func WithEvictionCallbackTimeout[T any](d time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.callbackTimeout = d
+	}
+}
+
+func WithStatsDisabled[T any]() Option[T] {
+	return func(c *Cache[T]) {
+		c.statsOff = true
+	}
+}
+
+func WithRejectPastExpireAt[T any]() Option[T] {
+	return func(c *Cache[T]) {
+		c.rejectPast = true
+	}
+}
+
+func WithCleanStrategy[T any](strategy CleanStrategy) Option[T] {
+	return func(c *Cache[T]) {
+		c.strategy = strategy
+	}
+}
+
+func WithLockMetrics[T any](sampleEvery int) Option[T] {
+	return func(c *Cache[T]) {
+		c.lockStats.every = uint64(max(sampleEvery, 0))
+	}
+}
+
+func WithDefaultTimeout[T any](d time.Duration) Option[T] {
+	return func(c *Cache[T]) {
+		c.defaultTimeout = d
+	}
+}
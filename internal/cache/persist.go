@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func (c *Cache[T]) persist(path string) error {
+	bytes, err := c.codec.Marshal(c.snapshot())
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, bytes)
+}
+
+func writeFile(path string, bytes []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(bytes); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *Cache[T]) startAutoPersist(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.autoPersistEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if err := c.autoPersist(); err != nil && c.logger != nil {
+					c.logger.Warn("memo: auto persist failed", "path", c.autoPersistPath, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (c *Cache[T]) autoPersist() error {
+	c.mu.RLock()
+	if c.items == nil {
+		c.mu.RUnlock()
+		return nil
+	}
+
+	entries := c.snapshot()
+	c.mu.RUnlock()
+
+	bytes, err := c.codec.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(c.autoPersistPath, bytes); err != nil {
+		return err
+	}
+
+	c.lastPersist.Store(c.now().UnixNano())
+	c.persistedBytes.Store(int64(len(bytes)))
+
+	return nil
+}
+
+func (c *Cache[T]) restoreFile(path string) error {
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries map[string]entry[T]
+	if err := c.codec.Unmarshal(bytes, &entries); err != nil {
+		return err
+	}
+
+	_, err = c.restore(entries)
+	return err
+}
+
+func (c *Cache[T]) persistTime() time.Time {
+	if at := c.lastPersist.Load(); at != 0 {
+		return time.Unix(0, at)
+	}
+
+	return time.Time{}
+}
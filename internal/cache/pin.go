@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrAllPinned = errors.New("cache is over capacity and all entries are pinned")
+
+func (c *Cache[T]) Pin(key string) error {
+	return c.setPinned(key, true)
+}
+
+func (c *Cache[T]) Unpin(key string) error {
+	return c.setPinned(key, false)
+}
+
+func (c *Cache[T]) setPinned(key string, pinned bool) error {
+	k := c.key(key)
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	item, exists := c.items[k]
+	if !exists || c.expired(item, c.now()) {
+		return fmt.Errorf("key %s does not exists", key)
+	}
+
+	item.pinned = pinned
+	return nil
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+type EvictionPolicy int
+
+const (
+	PolicyLRU EvictionPolicy = iota
+	PolicyFIFO
+)
+
+func (c *Cache[T]) SetEvictionPolicy(policy EvictionPolicy) error {
+	if policy != PolicyLRU && policy != PolicyFIFO {
+		return fmt.Errorf("unknown eviction policy %d", policy)
+	}
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	if c.evictionPolicy() == policy {
+		return nil
+	}
+
+	// FIFO doesn't track reads, so the recency left from an earlier LRU period
+	// is stale, LRU starts again from the insert order.
+	if policy == PolicyLRU && c.maxIdle <= 0 {
+		for _, v := range c.items {
+			v.lastAccess.Store(v.setAt.UnixNano())
+		}
+	}
+
+	c.policy.Store(int32(policy))
+	return nil
+}
+
+// evictionPolicy is atomic because touch reads it after Get has released the
+// read lock, while SetEvictionPolicy may change it.
+func (c *Cache[T]) evictionPolicy() EvictionPolicy {
+	return EvictionPolicy(c.policy.Load())
+}
+
+func (c *Cache[T]) evictBefore(a, b *Item[T]) bool {
+	if c.evictionPolicy() == PolicyFIFO {
+		if !a.setAt.Equal(b.setAt) {
+			return a.setAt.Before(b.setAt)
+		}
+
+		return a.version < b.version
+	}
+
+	if a.weight != b.weight {
+		return a.weight < b.weight
+	}
+
+	return a.lastAccess.Load() < b.lastAccess.Load()
+}
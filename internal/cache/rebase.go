@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type timedSnapshot[T any] struct {
+	TakenAt time.Time           `json:"taken_at"`
+	Entries map[string]entry[T] `json:"entries"`
+}
+
+func (c *Cache[T]) MarshalJSONSnapshot() ([]byte, error) {
+	if err := c.rlock(); err != nil {
+		return nil, err
+	}
+	if c.items == nil {
+		c.mu.RUnlock()
+		return nil, errors.New("cache is closed")
+	}
+
+	snap := timedSnapshot[T]{TakenAt: c.now(), Entries: c.snapshot()}
+	c.mu.RUnlock()
+
+	return json.Marshal(snap)
+}
+
+func (c *Cache[T]) UnmarshalJSONRebased(bytes []byte, now time.Time) error {
+	var snap timedSnapshot[T]
+	if err := json.Unmarshal(bytes, &snap); err != nil {
+		return err
+	}
+
+	if snap.TakenAt.IsZero() {
+		return errors.New("snapshot has no taken_at time")
+	}
+
+	for k, v := range snap.Entries {
+		left := v.TTL.Sub(snap.TakenAt)
+		if left <= 0 {
+			delete(snap.Entries, k)
+			continue
+		}
+
+		v.TTL = now.Add(left)
+		snap.Entries[k] = v
+	}
+
+	var onSet []func()
+	defer func() { runCallbacks(onSet) }()
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	var err error
+	onSet, err = c.restore(snap.Entries)
+	return err
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (c *Cache[T]) ReplaceAll(items map[string]T, ttl time.Duration) error {
+	var onSet []func()
+	defer func() {
+		for _, fn := range onSet {
+			fn()
+		}
+	}()
+
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return c.closedErr()
+	}
+
+	fresh := make(map[string]*Item[T], len(items))
+	var size, weight, keyBytes int64
+	var errs []error
+
+	for key, value := range items {
+		item := c.newItem(value, ttl)
+
+		k := c.key(key)
+		if err := c.checkKey(k); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if c.maxWeight > 0 && item.weight > c.maxWeight {
+			errs = append(errs, fmt.Errorf("weight %d of key %s exceeds max weight %d", item.weight, k, c.maxWeight))
+			continue
+		}
+
+		c.prepare(item)
+		if old, exists := fresh[k]; exists {
+			size -= old.size
+			weight -= old.weight
+		} else {
+			keyBytes += int64(len(k))
+		}
+
+		fresh[k] = item
+		size += item.size
+		weight += item.weight
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	old := c.items
+	c.items = fresh
+	c.rearmGrowth()
+	c.resetIndexes()
+	c.resetBuckets()
+	for k, v := range fresh {
+		c.indexAdd(k, v)
+		c.bucketAdd(k, v)
+	}
+	c.stat.SizeBytes = size
+	c.weight = weight
+	c.keyBytes = keyBytes
+
+	for k, v := range old {
+		if _, exists := fresh[k]; !exists {
+			c.notifyEvicted(context.Background(), k, v)
+		}
+	}
+
+	err := c.enforceCapacity("")
+	c.checkGrowth()
+
+	if c.onSet != nil {
+		for k, v := range fresh {
+			if c.items[k] == v {
+				onSet = append(onSet, c.setCallback(k, c.value(v), v))
+			}
+		}
+	}
+
+	return err
+}
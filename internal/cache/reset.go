@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/crewcrew23/memo/internal/stat"
+)
+
+func (c *Cache[T]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctx = ctx
+	c.cancel = cancel
+
+	c.items = make(map[string]*Item[T])
+	c.growth.fired = false
+	c.stat = &stat.Stats{}
+	c.hits.Store(0)
+	c.misses.Store(0)
+	c.window.Reset()
+	c.droppedEvictions.Store(0)
+	c.panicsRecovered.Store(0)
+	c.callbackTimeouts.Store(0)
+	c.lastPersist.Store(0)
+	c.persistedBytes.Store(0)
+	c.lockStats.reset()
+	c.breaker.reset()
+	c.weight = 0
+	c.keyBytes = 0
+	c.resetIndexes()
+	c.resetBuckets()
+
+	if c.evictBuffer > 0 && c.evictQueue == nil {
+		c.startEvictionWorker()
+	}
+
+	c.refreshMu.Lock()
+	c.refreshing = make(map[string]struct{})
+	if c.refreshWorkers > 0 {
+		c.startRefreshWorkers(c.refreshWorkers)
+	}
+	c.refreshMu.Unlock()
+
+	if c.autoPersistEvery > 0 {
+		c.startAutoPersist(ctx)
+	}
+
+	if c.cleanInterval > 0 {
+		startClean(c, ctx, c.cleanInterval)
+	}
+}
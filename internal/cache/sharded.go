@@ -0,0 +1,317 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/crewcrew23/memo/internal/stat"
+)
+
+const defaultShardCount = 16
+
+type ShardedCache[T any] struct {
+	shards []*Cache[T]
+	mask   uint64
+	hasher func(string) uint64
+	keyFn  func(string) string
+	cancel context.CancelFunc
+}
+
+func NewSharded[T any](ctx context.Context, cancel context.CancelFunc, opts ...Option[T]) *ShardedCache[T] {
+	cfg := &Cache[T]{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	n := shardCount(cfg.shardCount)
+
+	hasher := cfg.shardHasher
+	if hasher == nil {
+		hasher = fnvHash
+	}
+
+	s := &ShardedCache[T]{
+		shards: make([]*Cache[T], n),
+		mask:   uint64(n - 1),
+		hasher: hasher,
+		keyFn:  cfg.keyFn,
+		cancel: cancel,
+	}
+
+	for i := range s.shards {
+		s.shards[i] = New[T](ctx, nil, append(opts[:len(opts):len(opts)], shardFiles[T](i))...)
+	}
+
+	return s
+}
+
+func shardFiles[T any](i int) Option[T] {
+	return func(c *Cache[T]) {
+		for _, path := range []*string{&c.persistPath, &c.autoPersistPath, &c.restorePath} {
+			if *path != "" {
+				*path = fmt.Sprintf("%s.%d", *path, i)
+			}
+		}
+	}
+}
+
+func StartCleanSharded[T any](s *ShardedCache[T], ctx context.Context, interval time.Duration) {
+	for _, shard := range s.shards {
+		StartClean(shard, ctx, interval)
+	}
+}
+
+// shard hashes the transformed key so that keys WithKeyTransform maps to the
+// same entry always pick the same shard, the shard itself stores the key
+// transformed as well.
+func (s *ShardedCache[T]) shard(key string) *Cache[T] {
+	if s.keyFn != nil {
+		key = s.keyFn(key)
+	}
+
+	return s.shards[s.hasher(key)&s.mask]
+}
+
+func (s *ShardedCache[T]) Set(key string, value T, ttl time.Duration) error {
+	return s.shard(key).Set(key, value, ttl)
+}
+
+func (s *ShardedCache[T]) SetExpireAt(key string, value T, at time.Time) error {
+	return s.shard(key).SetExpireAt(key, value, at)
+}
+
+func (s *ShardedCache[T]) SetWithContext(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return s.shard(key).SetWithContext(ctx, key, value, ttl)
+}
+
+func (s *ShardedCache[T]) SetMany(items map[string]T, ttl time.Duration) map[string]error {
+	batches := make(map[*Cache[T]]map[string]T)
+	for key, value := range items {
+		shard := s.shard(key)
+		if batches[shard] == nil {
+			batches[shard] = make(map[string]T)
+		}
+		batches[shard][key] = value
+	}
+
+	var errs map[string]error
+	for shard, batch := range batches {
+		for key, err := range shard.SetMany(batch, ttl) {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[key] = err
+		}
+	}
+
+	return errs
+}
+
+func (s *ShardedCache[T]) Get(key string) (T, error) {
+	return s.shard(key).Get(key)
+}
+
+func (s *ShardedCache[T]) GetWithContext(ctx context.Context, key string) (T, error) {
+	return s.shard(key).GetWithContext(ctx, key)
+}
+
+func (s *ShardedCache[T]) Delete(key string) error {
+	return s.shard(key).Delete(key)
+}
+
+func (s *ShardedCache[T]) GetOrDefault(key string, def T) T {
+	return s.shard(key).GetOrDefault(key, def)
+}
+
+func (s *ShardedCache[T]) GetOrSet(key string, ttl time.Duration, fn func() (T, error)) (T, error) {
+	return s.shard(key).GetOrSet(key, ttl, fn)
+}
+
+func (s *ShardedCache[T]) Pin(key string) error {
+	return s.shard(key).Pin(key)
+}
+
+func (s *ShardedCache[T]) Unpin(key string) error {
+	return s.shard(key).Unpin(key)
+}
+
+func (s *ShardedCache[T]) Has(key string) bool {
+	return s.shard(key).Has(key)
+}
+
+func (s *ShardedCache[T]) Clear() (int, error) {
+	total := 0
+	for _, shard := range s.shards {
+		n, err := shard.Clear()
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+func (s *ShardedCache[T]) EvictFunc(pred func(key string, value T, ttl time.Duration) bool) int {
+	evicted := 0
+	for _, shard := range s.shards {
+		evicted += shard.EvictFunc(pred)
+	}
+
+	return evicted
+}
+
+func (s *ShardedCache[T]) RefreshMatching(pred func(key string) bool, ttl time.Duration) int {
+	refreshed := 0
+	for _, shard := range s.shards {
+		refreshed += shard.RefreshMatching(pred, ttl)
+	}
+
+	return refreshed
+}
+
+func (s *ShardedCache[T]) TrimTo(targetBytes int64) int {
+	trimmed := 0
+	for _, shard := range s.shards {
+		trimmed += shard.TrimTo(targetBytes / int64(len(s.shards)))
+	}
+
+	return trimmed
+}
+
+func (s *ShardedCache[T]) SetEvictionPolicy(policy EvictionPolicy) error {
+	for i, shard := range s.shards {
+		if err := shard.SetEvictionPolicy(policy); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ShardedCache[T]) TTLHistogram(buckets []time.Duration) map[time.Duration]int {
+	hist := make(map[time.Duration]int, len(buckets))
+	for _, shard := range s.shards {
+		for b, n := range shard.TTLHistogram(buckets) {
+			hist[b] += n
+		}
+	}
+
+	return hist
+}
+
+func (s *ShardedCache[T]) Healthy() error {
+	for i, shard := range s.shards {
+		if err := shard.Healthy(); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ShardedCache[T]) Stat() stat.Stats {
+	var total stat.Stats
+	var recentHits, recentMisses uint64
+
+	for _, shard := range s.shards {
+		st := shard.Stat()
+
+		total.StatsDisabled = total.StatsDisabled || st.StatsDisabled
+		total.Hits += st.Hits
+		total.Misses += st.Misses
+		total.Evictions += st.Evictions
+		total.SizeBytes += st.SizeBytes
+		total.OverheadBytes += st.OverheadBytes
+		total.Entries += st.Entries
+		total.MapSize += st.MapSize
+		total.RefreshQueued += st.RefreshQueued
+		total.RefreshInFlight += st.RefreshInFlight
+		total.LoadsInFlight += st.LoadsInFlight
+		total.CapacityEvictErrors += st.CapacityEvictErrors
+		total.SizeUnderflows += st.SizeUnderflows
+		total.ClampedTTLs += st.ClampedTTLs
+		total.PanicsRecovered += st.PanicsRecovered
+		total.CallbackTimeouts += st.CallbackTimeouts
+		total.RejectedSets += st.RejectedSets
+		total.DroppedEvictions += st.DroppedEvictions
+		total.LockWaitAvg += st.LockWaitAvg * time.Duration(st.LockWaitSamples)
+		total.LockWaitSamples += st.LockWaitSamples
+		total.LockWaitP99 = max(total.LockWaitP99, st.LockWaitP99)
+		total.PersistedBytes += st.PersistedBytes
+		if st.LastPersistAt.After(total.LastPersistAt) {
+			total.LastPersistAt = st.LastPersistAt
+		}
+		total.LoaderCircuitOpen = total.LoaderCircuitOpen || st.LoaderCircuitOpen
+		total.LoaderCircuitTrips += st.LoaderCircuitTrips
+		total.LoaderShortCircuits += st.LoaderShortCircuits
+
+		hits, misses := shard.window.Counts()
+		recentHits += hits
+		recentMisses += misses
+	}
+
+	if total.LockWaitSamples > 0 {
+		total.LockWaitAvg /= time.Duration(total.LockWaitSamples)
+	}
+
+	if requests := total.Hits + total.Misses; requests > 0 {
+		total.HitRate = float64(total.Hits) / float64(requests) * 100
+	}
+
+	if recent := recentHits + recentMisses; recent > 0 {
+		total.RecentHitRate = float64(recentHits) / float64(recent) * 100
+	}
+
+	return total
+}
+
+func (s *ShardedCache[T]) ShardStats() []stat.Stats {
+	stats := make([]stat.Stats, len(s.shards))
+	for i, shard := range s.shards {
+		stats[i] = shard.Stat()
+	}
+
+	return stats
+}
+
+func (s *ShardedCache[T]) Closed() bool {
+	return s.shards[0].Closed()
+}
+
+func (s *ShardedCache[T]) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	var errs []error
+	for _, shard := range s.shards {
+		if err := shard.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func shardCount(n int) int {
+	if n <= 0 {
+		return defaultShardCount
+	}
+
+	count := 1
+	for count < n {
+		count <<= 1
+	}
+
+	return count
+}
+
+func fnvHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
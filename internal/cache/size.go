@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+const mapSlotOverhead = 8
+
+func (c *Cache[T]) RecomputeSize() int64 {
+	if err := c.lock(); err != nil {
+		return 0
+	}
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		return c.stat.SizeBytes
+	}
+
+	var total int64
+	for _, v := range c.items {
+		if !v.compressed {
+			v.size = c.sizeOf(v.Value)
+		}
+		total += v.size
+	}
+
+	c.stat.SizeBytes = total
+
+	return total
+}
+
+func (c *Cache[T]) sizeOf(val T) int64 {
+	if c.sizer != nil {
+		return c.sizer(val)
+	}
+
+	return getSize(val)
+}
+
+func entryOverhead[T any]() int64 {
+	var item Item[T]
+	var key string
+	var ptr *Item[T]
+
+	return int64(unsafe.Sizeof(item)-unsafe.Sizeof(item.Value)) +
+		int64(unsafe.Sizeof(key)+unsafe.Sizeof(ptr)) + mapSlotOverhead
+}
+
+func getSize[T any](val T) int64 {
+	return sizeOf(reflect.ValueOf(&val).Elem())
+}
+
+func sizeOf(v reflect.Value) int64 {
+	size := int64(v.Type().Size())
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		size += int64(v.Type().Elem().Size())
+		if !v.IsNil() {
+			size += contentSize(v.Elem())
+		}
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			size += sizeOf(v.Elem())
+		}
+
+	default:
+		size += contentSize(v)
+	}
+
+	return size
+}
+
+func contentSize(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Slice:
+		return int64(v.Cap()) * int64(v.Type().Elem().Size())
+
+	case reflect.Map:
+		return int64(v.Len()) * int64(v.Type().Key().Size()+v.Type().Elem().Size())
+	}
+
+	return 0
+}
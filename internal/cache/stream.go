@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/crewcrew23/memo/internal/stat"
+)
+
+func (c *Cache[T]) StatsStream(ctx context.Context, every time.Duration) <-chan stat.Stats {
+	return statsStream(ctx, every, c.Stat)
+}
+
+func (s *ShardedCache[T]) StatsStream(ctx context.Context, every time.Duration) <-chan stat.Stats {
+	return statsStream(ctx, every, s.Stat)
+}
+
+func statsStream(ctx context.Context, every time.Duration, snapshot func() stat.Stats) <-chan stat.Stats {
+	ch := make(chan stat.Stats)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			select {
+			case ch <- snapshot():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type KeyFunc[K comparable] func(key K) string
+
+type TypedStore[K comparable, V any] struct {
+	cache *Cache[V]
+	keyFn KeyFunc[K]
+}
+
+func NewTypedStore[K comparable, V any](c *Cache[V], keyFn KeyFunc[K]) *TypedStore[K, V] {
+	return &TypedStore[K, V]{cache: c, keyFn: keyFn}
+}
+
+func (s *TypedStore[K, V]) Cache() *Cache[V] {
+	return s.cache
+}
+
+func (s *TypedStore[K, V]) Set(key K, value V, ttl time.Duration) error {
+	return s.cache.Set(s.keyFn(key), value, ttl)
+}
+
+func (s *TypedStore[K, V]) SetWithContext(ctx context.Context, key K, value V, ttl time.Duration) error {
+	return s.cache.SetWithContext(ctx, s.keyFn(key), value, ttl)
+}
+
+func (s *TypedStore[K, V]) Get(key K) (V, error) {
+	return s.cache.Get(s.keyFn(key))
+}
+
+func (s *TypedStore[K, V]) GetWithContext(ctx context.Context, key K) (V, error) {
+	return s.cache.GetWithContext(ctx, s.keyFn(key))
+}
+
+func (s *TypedStore[K, V]) GetOrSet(key K, ttl time.Duration, fn func() (V, error)) (V, error) {
+	return s.cache.GetOrSet(s.keyFn(key), ttl, fn)
+}
+
+func (s *TypedStore[K, V]) Has(key K) bool {
+	return s.cache.Has(s.keyFn(key))
+}
+
+func (s *TypedStore[K, V]) Delete(key K) error {
+	return s.cache.Delete(s.keyFn(key))
+}
+
+func (s *TypedStore[K, V]) Close() error {
+	return s.cache.Close()
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type ReadView[T any] struct {
+	c   *Cache[T]
+	now time.Time
+}
+
+func (c *Cache[T]) View(fn func(r ReadView[T])) error {
+	if err := c.rlock(); err != nil {
+		return err
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return errors.New("cache is closed")
+	}
+
+	fn(ReadView[T]{c: c, now: c.now()})
+	return nil
+}
+
+func (r ReadView[T]) Get(key string) (T, error) {
+	item, exists := r.c.items[r.c.key(key)]
+	if !exists {
+		return zero[T](), fmt.Errorf("key %s does not exists", key)
+	}
+
+	if r.c.expired(item, r.now) {
+		return zero[T](), fmt.Errorf("TTL of key %s has expire", key)
+	}
+
+	return r.c.copyValue(r.c.value(item)), nil
+}
+
+func (r ReadView[T]) Has(key string) bool {
+	item, exists := r.c.items[r.c.key(key)]
+	return exists && !r.c.expired(item, r.now)
+}
+
+func (r ReadView[T]) Keys() []string {
+	keys := make([]string, 0, len(r.c.items))
+	for k, v := range r.c.items {
+		if !r.c.expired(v, r.now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c *Cache[T]) Keys() []string {
+	if err := c.rlock(); err != nil {
+		return nil
+	}
+	defer c.mu.RUnlock()
+
+	if c.items == nil {
+		return nil
+	}
+
+	return ReadView[T]{c: c, now: c.now()}.Keys()
+}
+
+func (c *Cache[T]) Len() int {
+	if err := c.rlock(); err != nil {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	return c.live()
+}
+
+func (c *Cache[T]) live() int {
+	n := 0
+	now := c.now()
+	for _, v := range c.items {
+		if !c.expired(v, now) {
+			n++
+		}
+	}
+
+	return n
+}
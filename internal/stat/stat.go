@@ -1,9 +1,48 @@
 package stat
 
+import (
+	"fmt"
+	"time"
+)
+
 type Stats struct {
-	Hits      uint64
-	Misses    uint64
-	Evictions uint64
-	HitRate   float64
-	SizeBytes int64
+	StatsDisabled bool `json:"stats_disabled"`
+
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Evictions     uint64  `json:"evictions"`
+	HitRate       float64 `json:"hit_rate"`
+	RecentHitRate float64 `json:"recent_hit_rate"`
+	SizeBytes     int64   `json:"size_bytes"`
+	OverheadBytes int64   `json:"overhead_bytes"`
+	Entries       int     `json:"entries"`
+	MapSize       int     `json:"map_size"`
+
+	RefreshQueued   int64 `json:"refresh_queued"`
+	RefreshInFlight int64 `json:"refresh_in_flight"`
+	LoadsInFlight   int64 `json:"loads_in_flight"`
+
+	CapacityEvictErrors uint64 `json:"capacity_evict_errors"`
+	SizeUnderflows      uint64 `json:"size_underflows"`
+	ClampedTTLs         uint64 `json:"clamped_ttls"`
+	PanicsRecovered     uint64 `json:"panics_recovered"`
+	CallbackTimeouts    uint64 `json:"callback_timeouts"`
+	RejectedSets        uint64 `json:"rejected_sets"`
+	DroppedEvictions    uint64 `json:"dropped_evictions"`
+
+	LockWaitSamples uint64        `json:"lock_wait_samples"`
+	LockWaitAvg     time.Duration `json:"lock_wait_avg"`
+	LockWaitP99     time.Duration `json:"lock_wait_p99"`
+
+	LastPersistAt  time.Time `json:"last_persist_at"`
+	PersistedBytes int64     `json:"persisted_bytes"`
+
+	LoaderCircuitOpen   bool   `json:"loader_circuit_open"`
+	LoaderCircuitTrips  uint64 `json:"loader_circuit_trips"`
+	LoaderShortCircuits uint64 `json:"loader_short_circuits"`
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d hit_rate=%.2f evictions=%d size_bytes=%d overhead_bytes=%d",
+		s.Hits, s.Misses, s.HitRate, s.Evictions, s.SizeBytes, s.OverheadBytes)
 }
@@ -0,0 +1,89 @@
+package stat
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type bucket struct {
+	slot   atomic.Int64
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type Window struct {
+	buckets []bucket
+	width   int64
+}
+
+func NewWindow(d time.Duration, n int) *Window {
+	if n <= 0 {
+		n = 1
+	}
+
+	width := int64(d) / int64(n)
+	if width <= 0 {
+		width = 1
+	}
+
+	return &Window{
+		buckets: make([]bucket, n),
+		width:   width,
+	}
+}
+
+func (w *Window) Hit() {
+	w.current().hits.Add(1)
+}
+
+func (w *Window) Miss() {
+	w.current().misses.Add(1)
+}
+
+func (w *Window) HitRate() float64 {
+	hits, misses := w.Counts()
+
+	total := hits + misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(total) * 100
+}
+
+func (w *Window) Counts() (hits, misses uint64) {
+	now := time.Now().UnixNano() / w.width
+	n := int64(len(w.buckets))
+
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if now-b.slot.Load() < n {
+			hits += b.hits.Load()
+			misses += b.misses.Load()
+		}
+	}
+
+	return hits, misses
+}
+
+func (w *Window) Reset() {
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		b.slot.Store(0)
+		b.hits.Store(0)
+		b.misses.Store(0)
+	}
+}
+
+func (w *Window) current() *bucket {
+	slot := time.Now().UnixNano() / w.width
+	b := &w.buckets[slot%int64(len(w.buckets))]
+
+	old := b.slot.Load()
+	if old != slot && b.slot.CompareAndSwap(old, slot) {
+		b.hits.Store(0)
+		b.misses.Store(0)
+	}
+
+	return b
+}
@@ -0,0 +1,57 @@
+package memo
+
+import (
+	"context"
+	"time"
+
+	"github.com/crewcrew23/memo/internal/cache"
+	"github.com/crewcrew23/memo/internal/stat"
+)
+
+type Stats = stat.Stats
+
+type KV[T any] = cache.KV[T]
+
+type EntryInfo[T any] = cache.EntryInfo[T]
+
+type Any = cache.Any
+
+type ByteCache = cache.ByteCache
+
+type ReadView[T any] = cache.ReadView[T]
+
+type KeyFunc[K comparable] = cache.KeyFunc[K]
+
+type TypedStore[K comparable, V any] = cache.TypedStore[K, V]
+
+// ErrTypeMismatch is returned by the typed getters of Any when the stored
+// value has a different type.
+var ErrTypeMismatch = cache.ErrTypeMismatch
+
+// Key joins parts into one key, every part is prefixed with its length so
+// different parts never give the same key: Key("a", "bc") is "1:a2:bc".
+func Key(parts ...string) string {
+	return cache.Key(parts...)
+}
+
+// GetAs returns the value of key from an Any cache as T.
+func GetAs[T any](a *Any, key string) (T, error) {
+	return cache.GetAs[T](a, key)
+}
+
+type Cache[T any] interface {
+	Set(key string, value T, ttl time.Duration) error
+	SetWithContext(ctx context.Context, key string, value T, ttl time.Duration) error
+	Get(key string) (T, error)
+	GetWithContext(ctx context.Context, key string) (T, error)
+	Delete(key string) error
+	Has(key string) bool
+	Clear() (int, error)
+	Stat() Stats
+	Close() error
+}
+
+var (
+	_ Cache[any] = (*cache.Cache[any])(nil)
+	_ Cache[any] = (*cache.ShardedCache[any])(nil)
+)
@@ -7,9 +7,50 @@ import (
 	"github.com/crewcrew23/memo/internal/cache"
 )
 
-func New[T any]() *cache.Cache[T] {
+func New[T any](opts ...Option[T]) *cache.Cache[T] {
 	ctx, cancel := context.WithCancel(context.Background())
-	c := cache.New[T](ctx, cancel)
+	c := cache.New[T](ctx, cancel, opts...)
 	cache.StartClean(c, ctx, time.Minute*5)
 	return c
 }
+
+func NewBare[T any](opts ...Option[T]) *cache.Cache[T] {
+	return cache.New[T](nil, nil, append(opts, cache.WithCleanStrategy[T](cache.StrategyLazy))...)
+}
+
+func NewWithContext[T any](ctx context.Context, opts ...Option[T]) *cache.Cache[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	c := cache.New[T](ctx, cancel, opts...)
+	cache.StartClean(c, ctx, time.Minute*5)
+	cache.CloseWhenDone(c, ctx)
+	return c
+}
+
+func From[T any](src *cache.Cache[T], opts ...Option[T]) *cache.Cache[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := cache.From[T](src, ctx, cancel, opts...)
+	cache.StartClean(c, ctx, time.Minute*5)
+	return c
+}
+
+func NewSharded[T any](opts ...Option[T]) *cache.ShardedCache[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := cache.NewSharded[T](ctx, cancel, opts...)
+	cache.StartCleanSharded(c, ctx, time.Minute*5)
+	return c
+}
+
+func NewAny(opts ...Option[any]) *cache.Any {
+	return cache.NewAny(New[any](opts...))
+}
+
+func NewTypedStore[K comparable, V any](keyFn KeyFunc[K], opts ...Option[V]) *cache.TypedStore[K, V] {
+	return cache.NewTypedStore[K, V](New[V](opts...), keyFn)
+}
+
+func NewByteCache(opts ...Option[[]byte]) *cache.ByteCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := cache.NewByteCache(ctx, cancel, opts...)
+	cache.StartClean(c.Cache(), ctx, time.Minute*5)
+	return c
+}
@@ -0,0 +1,373 @@
+package memo
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/crewcrew23/memo/internal/cache"
+)
+
+type Option[T any] = cache.Option[T]
+
+type Loader[T any] = cache.Loader[T]
+
+type TTLLoader[T any] = cache.TTLLoader[T]
+
+// ErrLoaderCircuitOpen is returned by Load while the loader circuit breaker
+// is open and there is no stale value for the key.
+var ErrLoaderCircuitOpen = cache.ErrLoaderCircuitOpen
+
+type Clock = cache.Clock
+
+type Codec = cache.Codec
+
+type JSONCodec = cache.JSONCodec
+
+type Compressor = cache.Compressor
+
+type GzipCompressor = cache.GzipCompressor
+
+type MergeStrategy = cache.MergeStrategy
+
+const (
+	Overwrite    = cache.Overwrite
+	KeepExisting = cache.KeepExisting
+	KeepNewer    = cache.KeepNewer
+)
+
+type CleanStrategy = cache.CleanStrategy
+
+const (
+	StrategyActive = cache.StrategyActive
+	StrategyLazy   = cache.StrategyLazy
+)
+
+type BusyPolicy = cache.BusyPolicy
+
+// ErrAllPinned is returned by Set when the cache is over WithMaxEntries or
+// WithMaxWeight and every other live entry is pinned, the Set is undone.
+var ErrAllPinned = cache.ErrAllPinned
+
+// ErrKeyTooLong is returned by Set when WithMaxKeyLength is used and the key
+// is longer than the limit.
+var ErrKeyTooLong = cache.ErrKeyTooLong
+
+// ErrTimeout is returned when WithDefaultTimeout is set and the cache lock
+// can't be taken in time.
+var ErrTimeout = cache.ErrTimeout
+
+const (
+	BusyWait  = cache.BusyWait
+	BusyError = cache.BusyError
+)
+
+// ErrLoadBusy is returned by Load when WithMaxConcurrentLoads is used with
+// BusyError and all load slots are taken.
+var ErrLoadBusy = cache.ErrLoadBusy
+
+type EvictionPolicy = cache.EvictionPolicy
+
+const (
+	PolicyLRU  = cache.PolicyLRU
+	PolicyFIFO = cache.PolicyFIFO
+)
+
+type DuplicatePolicy = cache.DuplicatePolicy
+
+const (
+	DuplicateLastWins = cache.DuplicateLastWins
+	DuplicateError    = cache.DuplicateError
+)
+
+// ErrDuplicateKey is reported by SetMany and UnmarshalNDJSON with
+// DuplicateError when a batch has the same key twice.
+var ErrDuplicateKey = cache.ErrDuplicateKey
+
+type QueuePolicy = cache.QueuePolicy
+
+const (
+	QueueBlock = cache.QueueBlock
+	QueueDrop  = cache.QueueDrop
+)
+
+type ClosedPolicy = cache.ClosedPolicy
+
+const (
+	ClosedError  = cache.ClosedError
+	ClosedPanic  = cache.ClosedPanic
+	ClosedIgnore = cache.ClosedIgnore
+)
+
+// WithDeepCopyOnGet makes Get return a copy of slice, map and array values
+// so callers can't mutate the cached value in place.
+func WithDeepCopyOnGet[T any]() Option[T] {
+	return cache.WithDeepCopyOnGet[T]()
+}
+
+// WithCloneOnGet applies fn to every value returned by Get and the other
+// reads, so callers get their own copy of pointer values.
+func WithCloneOnGet[T any](fn func(T) T) Option[T] {
+	return cache.WithCloneOnGet[T](fn)
+}
+
+// WithValueEquals makes Set compare the new value with the live one using fn.
+// When they are equal only the TTL is refreshed, the value is kept and OnSet
+// is not called. Without it every Set is a change.
+func WithValueEquals[T any](fn func(a, b T) bool) Option[T] {
+	return cache.WithValueEquals[T](fn)
+}
+
+// WithTTLPrecision truncates every stored expiry time down to a multiple of d,
+// so snapshots taken moments apart are equal. Entries may expire up to d
+// earlier than asked.
+func WithTTLPrecision[T any](d time.Duration) Option[T] {
+	return cache.WithTTLPrecision[T](d)
+}
+
+// WithStaleWindow keeps an entry for d after its TTL has passed, so that
+// GetStale can still return it. Get treats such entries as expired.
+func WithStaleWindow[T any](d time.Duration) Option[T] {
+	return cache.WithStaleWindow[T](d)
+}
+
+// WithHitRateWindow sets the window over which Stat().RecentHitRate is
+// computed. The window is split into the given number of buckets.
+func WithHitRateWindow[T any](d time.Duration, buckets int) Option[T] {
+	return cache.WithHitRateWindow[T](d, buckets)
+}
+
+// WithKeyTransform applies fn to every key before it is used, e.g. to hash
+// long keys. Collisions produced by fn are the caller's responsibility.
+func WithKeyTransform[T any](fn func(string) string) Option[T] {
+	return cache.WithKeyTransform[T](fn)
+}
+
+// WithMaxAge caps the lifetime of every entry to d since it was set,
+// regardless of the TTL passed to Set.
+func WithMaxAge[T any](d time.Duration) Option[T] {
+	return cache.WithMaxAge[T](d)
+}
+
+// WithInitialCapacity preallocates room for n entries. Negative values are
+// treated as zero.
+func WithInitialCapacity[T any](n int) Option[T] {
+	return cache.WithInitialCapacity[T](n)
+}
+
+// WithPersistOnClose makes Close write a JSON snapshot of the live entries
+// to path before the cache is torn down.
+func WithPersistOnClose[T any](path string) Option[T] {
+	return cache.WithPersistOnClose[T](path)
+}
+
+// WithAutoPersist writes a snapshot of the live entries to path every
+// interval, the goroutine stops when the cache is closed.
+func WithAutoPersist[T any](path string, every time.Duration) Option[T] {
+	return cache.WithAutoPersist[T](path, every)
+}
+
+// WithRestoreOnStart loads the snapshot written by WithAutoPersist or
+// WithPersistOnClose when the cache is created. A missing file is ignored.
+func WithRestoreOnStart[T any](path string) Option[T] {
+	return cache.WithRestoreOnStart[T](path)
+}
+
+// WithLoader sets the function used by Load and by background refreshes.
+// Loaded values are stored with the given ttl.
+func WithLoader[T any](fn Loader[T], ttl time.Duration) Option[T] {
+	return cache.WithLoader[T](fn, ttl)
+}
+
+// WithTTLLoader is like WithLoader but the loader returns the TTL of every
+// value. A zero TTL is replaced by zeroTTL, a TTL <= 0 means the value is
+// returned but not cached.
+func WithTTLLoader[T any](fn TTLLoader[T], zeroTTL time.Duration) Option[T] {
+	return cache.WithTTLLoader[T](fn, zeroTTL)
+}
+
+// WithRefreshWorkers runs background refreshes on a pool of n workers
+// instead of a goroutine per refresh.
+func WithRefreshWorkers[T any](n int) Option[T] {
+	return cache.WithRefreshWorkers[T](n)
+}
+
+// WithClosedPolicy sets what Set does on a closed cache: return an error
+// (default), panic or silently do nothing.
+func WithClosedPolicy[T any](policy ClosedPolicy) Option[T] {
+	return cache.WithClosedPolicy[T](policy)
+}
+
+// WithMaxWeight limits the total weight of stored entries. When the limit
+// is exceeded the entries with the lowest weight are evicted first.
+func WithMaxWeight[T any](max int64) Option[T] {
+	return cache.WithMaxWeight[T](max)
+}
+
+// WithMaxIdle expires entries that were not read for longer than d, even if
+// their TTL has not passed yet.
+func WithMaxIdle[T any](d time.Duration) Option[T] {
+	return cache.WithMaxIdle[T](d)
+}
+
+// WithShardCount sets the number of shards of a sharded cache. It is rounded
+// up to a power of two. The default is 16.
+func WithShardCount[T any](n int) Option[T] {
+	return cache.WithShardCount[T](n)
+}
+
+// WithShardHasher sets the hash used to pick the shard of a key. The default
+// is FNV-1a.
+func WithShardHasher[T any](fn func(string) uint64) Option[T] {
+	return cache.WithShardHasher[T](fn)
+}
+
+// WithClock sets the clock used to compute expiry, see the memotest package
+// for a clock that can be moved by hand in tests.
+func WithClock[T any](clock Clock) Option[T] {
+	return cache.WithClock[T](clock)
+}
+
+// WithLogger logs evictions, loader and refresh failures and close events.
+// Nothing is logged when no logger is set.
+func WithLogger[T any](logger *slog.Logger) Option[T] {
+	return cache.WithLogger[T](logger)
+}
+
+// WithAsyncEviction calls OnEvicted and OnEvictedContext from one goroutine
+// in eviction order. When bufferSize events are queued QueueBlock waits for
+// space and QueueDrop drops the event and counts it in DroppedEvictions.
+func WithAsyncEviction[T any](bufferSize int, policy QueuePolicy) Option[T] {
+	return cache.WithAsyncEviction[T](bufferSize, policy)
+}
+
+// WithEvictionLogSampling logs only every n-th entry removed by a cleanup
+// sweep at debug level. The sweep summary is always logged.
+func WithEvictionLogSampling[T any](every int) Option[T] {
+	return cache.WithEvictionLogSampling[T](every)
+}
+
+// WithEvictionSampleSize makes capacity eviction look at k random entries
+// and evict the least recently used of them instead of scanning the whole
+// cache.
+func WithEvictionSampleSize[T any](k int) Option[T] {
+	return cache.WithEvictionSampleSize[T](k)
+}
+
+// WithTTLBounds clamps the TTL passed to Set into [min, max]. Zero disables
+// a bound.
+func WithTTLBounds[T any](min, max time.Duration) Option[T] {
+	return cache.WithTTLBounds[T](min, max)
+}
+
+// WithLoaderCircuitBreaker stops calling the loader for cooldown after
+// threshold consecutive failures within window.
+func WithLoaderCircuitBreaker[T any](threshold int, window, cooldown time.Duration) Option[T] {
+	return cache.WithLoaderCircuitBreaker[T](threshold, window, cooldown)
+}
+
+// WithCodec sets the format used by Save, Restore and WithPersistOnClose.
+// The default is JSONCodec.
+func WithCodec[T any](codec Codec) Option[T] {
+	return cache.WithCodec[T](codec)
+}
+
+// WithMaxEntries limits the number of entries in the cache. Expired entries
+// are dropped first, then the least recently used ones.
+func WithMaxEntries[T any](n int) Option[T] {
+	return cache.WithMaxEntries[T](n)
+}
+
+// WithGrowthAlert calls fn once when Set makes the cache hold more than
+// threshold live entries, and again only after removals have brought it back
+// to threshold or below. fn runs under the cache lock and must not call the cache.
+func WithGrowthAlert[T any](threshold int, fn func(count int)) Option[T] {
+	return cache.WithGrowthAlert[T](threshold, fn)
+}
+
+// WithDuplicateKeys sets what SetMany and UnmarshalNDJSON do when a batch
+// has the same key twice: DuplicateLastWins (default) stores the last value,
+// DuplicateError keeps the first one and reports ErrDuplicateKey.
+func WithDuplicateKeys[T any](policy DuplicatePolicy) Option[T] {
+	return cache.WithDuplicateKeys[T](policy)
+}
+
+// WithMaxKeyLength makes Set return ErrKeyTooLong for keys longer than n
+// bytes, the cache is left unchanged.
+func WithMaxKeyLength[T any](n int) Option[T] {
+	return cache.WithMaxKeyLength[T](n)
+}
+
+// WithEvictionPolicy picks which live entry is evicted when the cache is over
+// WithMaxEntries or WithMaxWeight. PolicyLRU (default) evicts the lowest weight,
+// then the least recently used entry, PolicyFIFO the oldest inserted one.
+func WithEvictionPolicy[T any](policy EvictionPolicy) Option[T] {
+	return cache.WithEvictionPolicy[T](policy)
+}
+
+// WithSizer sets the function used to compute the size of a value in bytes
+// instead of the reflection based estimate.
+func WithSizer[T any](fn func(T) int64) Option[T] {
+	return cache.WithSizer[T](fn)
+}
+
+// WithMaxConcurrentLoads limits the number of loader calls running at once
+// across all keys. With BusyWait callers wait for a free slot, with BusyError
+// Load returns ErrLoadBusy.
+func WithMaxConcurrentLoads[T any](n int, policy BusyPolicy) Option[T] {
+	return cache.WithMaxConcurrentLoads[T](n, policy)
+}
+
+// WithCompression compresses values of at least threshold bytes with comp.
+// Values that don't get smaller are stored as is.
+func WithCompression[T ~[]byte | ~string](comp Compressor, threshold int) Option[T] {
+	return cache.WithCompression[T](comp, threshold)
+}
+
+// WithDrainOnClose makes Close call fn for every live entry before the
+// entries are dropped.
+func WithDrainOnClose[T any](fn func(key string, value T)) Option[T] {
+	return cache.WithDrainOnClose[T](fn)
+}
+
+// WithLoaderRetry calls a failing loader up to maxAttempts times, waiting
+// baseDelay, 2*baseDelay, 4*baseDelay and so on between attempts.
+func WithLoaderRetry[T any](maxAttempts int, baseDelay time.Duration) Option[T] {
+	return cache.WithLoaderRetry[T](maxAttempts, baseDelay)
+}
+
+// WithEvictionCallbackTimeout stops waiting for OnEvicted and
+// OnEvictedContext callbacks that run longer than d.
+func WithEvictionCallbackTimeout[T any](d time.Duration) Option[T] {
+	return cache.WithEvictionCallbackTimeout[T](d)
+}
+
+// WithStatsDisabled stops counting hits and misses so reads don't write
+// any shared counters.
+func WithStatsDisabled[T any]() Option[T] {
+	return cache.WithStatsDisabled[T]()
+}
+
+// WithRejectPastExpireAt makes SetExpireAt return an error for a time that is
+// not in the future instead of storing an already expired entry.
+func WithRejectPastExpireAt[T any]() Option[T] {
+	return cache.WithRejectPastExpireAt[T]()
+}
+
+// WithCleanStrategy chooses how expired entries are removed: by the background
+// cleanup (StrategyActive, default) or only when they are read (StrategyLazy).
+func WithCleanStrategy[T any](strategy CleanStrategy) Option[T] {
+	return cache.WithCleanStrategy[T](strategy)
+}
+
+// WithLockMetrics measures how long every sampleEvery-th call waits for the
+// cache lock and reports it in Stat. Disabled by default.
+func WithLockMetrics[T any](sampleEvery int) Option[T] {
+	return cache.WithLockMetrics[T](sampleEvery)
+}
+
+// WithDefaultTimeout limits how long methods without a context wait for the
+// cache lock. They return ErrTimeout, or an empty result when they have no
+// error, when it runs out. Close, Closed, Reset and Stat always wait.
+func WithDefaultTimeout[T any](d time.Duration) Option[T] {
+	return cache.WithDefaultTimeout[T](d)
+}
@@ -0,0 +1,73 @@
+package memo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/crewcrew23/memo/internal/cache"
+)
+
+type closer interface {
+	Close() error
+}
+
+type Registry struct {
+	mu     sync.RWMutex
+	caches map[string]closer
+	order  []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		caches: make(map[string]closer),
+	}
+}
+
+func Register[T any](r *Registry, name string, c *cache.Cache[T]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.caches[name]; exists {
+		return fmt.Errorf("cache %s is already registered", name)
+	}
+
+	r.caches[name] = c
+	r.order = append(r.order, name)
+	return nil
+}
+
+func Get[T any](r *Registry, name string) (*cache.Cache[T], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, exists := r.caches[name]
+	if !exists {
+		return nil, fmt.Errorf("cache %s is not registered", name)
+	}
+
+	c, ok := v.(*cache.Cache[T])
+	if !ok {
+		return nil, fmt.Errorf("cache %s has type %T, not %T", name, v, c)
+	}
+
+	return c, nil
+}
+
+func (r *Registry) CloseAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for i := len(r.order) - 1; i >= 0; i-- {
+		name := r.order[i]
+		if err := r.caches[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close cache %s: %w", name, err))
+		}
+	}
+
+	r.caches = make(map[string]closer)
+	r.order = nil
+
+	return errors.Join(errs...)
+}
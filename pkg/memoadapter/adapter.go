@@ -0,0 +1,51 @@
+package memoadapter
+
+import (
+	"time"
+
+	"github.com/crewcrew23/memo/internal/cache"
+)
+
+type HTTPCache struct {
+	c   *cache.Cache[[]byte]
+	ttl time.Duration
+}
+
+func NewHTTPCache(c *cache.Cache[[]byte], ttl time.Duration) *HTTPCache {
+	return &HTTPCache{c: c, ttl: ttl}
+}
+
+func (h *HTTPCache) Get(key string) ([]byte, bool) {
+	val, err := h.c.Get(key)
+	return val, err == nil
+}
+
+func (h *HTTPCache) Set(key string, value []byte) {
+	h.c.Set(key, value, h.ttl)
+}
+
+func (h *HTTPCache) Delete(key string) {
+	h.c.Delete(key)
+}
+
+type ByteKeys struct {
+	c   *cache.Cache[[]byte]
+	ttl time.Duration
+}
+
+func NewByteKeys(c *cache.Cache[[]byte], ttl time.Duration) *ByteKeys {
+	return &ByteKeys{c: c, ttl: ttl}
+}
+
+func (b *ByteKeys) Get(key []byte) ([]byte, bool) {
+	val, err := b.c.Get(string(key))
+	return val, err == nil
+}
+
+func (b *ByteKeys) Set(key, value []byte) {
+	b.c.Set(string(key), value, b.ttl)
+}
+
+func (b *ByteKeys) Delete(key []byte) {
+	b.c.Delete(string(key))
+}
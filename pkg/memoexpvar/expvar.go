@@ -0,0 +1,26 @@
+package memoexpvar
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+
+	"github.com/crewcrew23/memo/internal/cache"
+)
+
+var mu sync.Mutex
+
+func PublishExpvar[T any](name string, c *cache.Cache[T]) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %s is already published", name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() any {
+		return c.Stat()
+	}))
+
+	return nil
+}
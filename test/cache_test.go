@@ -1,12 +1,30 @@
 package test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/crewcrew23/memo/internal/cache"
 	"github.com/crewcrew23/memo/pkg/memo"
+	"github.com/crewcrew23/memo/pkg/memoadapter"
+	"github.com/crewcrew23/memo/pkg/memoexpvar"
+	"github.com/crewcrew23/memo/pkg/memotest"
 )
 
 type TestData struct {
@@ -208,3 +226,2576 @@ func TestCloseConn(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestClosed(t *testing.T) {
+	c := memo.New[int]()
+	if c.Closed() {
+		t.Fail()
+	}
+
+	c.Close()
+	if !c.Closed() {
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cc := memo.NewWithContext[int](ctx)
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for !cc.Closed() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !cc.Closed() {
+		t.Fail()
+	}
+}
+
+func TestDeepCopyOnGet(t *testing.T) {
+	c := memo.New[[]int](memo.WithDeepCopyOnGet[[]int]())
+
+	c.Set("key", []int{1, 2, 3}, time.Second*5)
+
+	val, err := c.Get("key")
+	if err != nil {
+		t.Fail()
+	}
+	val[0] = 100
+
+	val, _ = c.Get("key")
+	if val[0] != 1 {
+		t.Fail()
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key", &TestData{5}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 10)
+
+	val, stale, err := c.GetStale("key")
+	if err != nil || !stale || val.Value != 5 {
+		t.Fail()
+	}
+
+	if _, _, err := c.GetStale("missing"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestStaleWindow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := cache.New[*TestData](ctx, cancel, cache.WithStaleWindow[*TestData](time.Second*5))
+	cache.StartClean(c, ctx, time.Millisecond*10)
+
+	c.Set("key", &TestData{5}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 50)
+
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+
+	if _, stale, err := c.GetStale("key"); err != nil || !stale {
+		t.Fail()
+	}
+}
+
+func TestRecentHitRate(t *testing.T) {
+	c := memo.New[*TestData](memo.WithHitRateWindow[*TestData](time.Millisecond*50, 5))
+
+	c.Set("key", &TestData{5}, time.Second*5)
+	c.Get("key")
+	c.Get("missing")
+
+	if rate := c.Stat().RecentHitRate; rate != 50 {
+		t.Fail()
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	c.Get("key")
+
+	if rate := c.Stat().RecentHitRate; rate != 100 {
+		t.Fail()
+	}
+}
+
+func TestNewWithContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := memo.NewWithContext[*TestData](ctx)
+
+	if err := c.Set("key", &TestData{5}, time.Second*5); err != nil {
+		t.Fail()
+	}
+
+	cancel()
+	time.Sleep(time.Millisecond * 10)
+
+	if err := c.Set("key", &TestData{5}, time.Second*5); err == nil {
+		t.Fail()
+	}
+
+	c.Close()
+}
+
+func TestKeyTransform(t *testing.T) {
+	c := memo.New[*TestData](memo.WithKeyTransform[*TestData](strings.ToLower))
+
+	c.Set("KEY", &TestData{5}, time.Second*5)
+
+	if _, err := c.Get("key"); err != nil {
+		t.Fail()
+	}
+
+	if _, _, err := c.GetStale("Key"); err != nil {
+		t.Fail()
+	}
+}
+
+func TestMarshalKeys(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key1", &TestData{1}, time.Second*5)
+	c.Set("key2", &TestData{2}, time.Second*5)
+	c.Set("key3", &TestData{3}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 10)
+
+	bytes, err := c.MarshalKeys([]string{"key1", "key3", "missing"})
+	if err != nil {
+		t.Fail()
+	}
+
+	uc := memo.New[*TestData]()
+	if err := uc.UnmarshalJSON(bytes); err != nil {
+		t.Fail()
+	}
+
+	if _, err := uc.Get("key1"); err != nil {
+		t.Fail()
+	}
+
+	if _, err := uc.Get("key2"); err == nil {
+		t.Fail()
+	}
+
+	if _, _, err := uc.GetStale("key3"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestFrom(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key", &TestData{5}, time.Second*5)
+	c.Get("key")
+
+	clone := memo.From(c)
+	c.Close()
+
+	if val, err := clone.Get("key"); err != nil || val.Value != 5 {
+		t.Fail()
+	}
+
+	if clone.Stat().Hits != 1 {
+		t.Fail()
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	c := memo.New[*TestData](memo.WithMaxAge[*TestData](time.Millisecond * 5))
+
+	c.Set("key", &TestData{5}, time.Hour)
+	time.Sleep(time.Millisecond * 10)
+
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSetOrGet(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	if val, loaded, err := c.SetOrGet("key", &TestData{1}, time.Second*5); err != nil || loaded || val.Value != 1 {
+		t.Fail()
+	}
+
+	if val, loaded, err := c.SetOrGet("key", &TestData{2}, time.Second*5); err != nil || !loaded || val.Value != 1 {
+		t.Fail()
+	}
+}
+
+func TestSetOrGetRejected(t *testing.T) {
+	c := memo.New[int](memo.WithMaxKeyLength[int](3))
+
+	if val, loaded, err := c.SetOrGet("long key", 1, time.Minute); !errors.Is(err, memo.ErrKeyTooLong) || loaded || val != 0 {
+		t.Fail()
+	}
+
+	if c.Has("long key") {
+		t.Fail()
+	}
+
+	c.Close()
+	if _, _, err := c.SetOrGet("key", 1, time.Minute); err == nil {
+		t.Fail()
+	}
+}
+
+// expvarRuns keeps the published names unique, expvar is process-wide and
+// rejects a name published twice, e.g. under go test -count.
+var expvarRuns atomic.Int64
+
+func TestPublishExpvar(t *testing.T) {
+	c := memo.New[*TestData]()
+	c.Set("key", &TestData{5}, time.Second*5)
+	c.Get("key")
+
+	name := "memo_test_" + strconv.FormatInt(expvarRuns.Add(1), 10)
+	if err := memoexpvar.PublishExpvar(name, c); err != nil {
+		t.Fail()
+	}
+
+	if err := memoexpvar.PublishExpvar(name, c); err == nil {
+		t.Fail()
+	}
+
+	if !strings.Contains(expvar.Get(name).String(), `"hits":1`) {
+		t.Fail()
+	}
+}
+
+func TestInitialCapacity(t *testing.T) {
+	c := memo.New[*TestData](memo.WithInitialCapacity[*TestData](-1))
+
+	if err := c.Set("key", &TestData{5}, time.Second*5); err != nil {
+		t.Fail()
+	}
+}
+
+func TestPersistOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := memo.New[*TestData](memo.WithPersistOnClose[*TestData](path))
+
+	c.Set("key", &TestData{5}, time.Second*5)
+	if err := c.Close(); err != nil {
+		t.Fail()
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uc := memo.New[*TestData]()
+	if err := uc.UnmarshalJSON(bytes); err != nil {
+		t.Fail()
+	}
+
+	if _, err := uc.Get("key"); err != nil {
+		t.Fail()
+	}
+}
+
+func TestPersistOnClose_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "cache.json")
+	c := memo.New[*TestData](memo.WithPersistOnClose[*TestData](path))
+
+	if err := c.Close(); err == nil {
+		t.Fail()
+	}
+
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestAutoPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := memo.New[*TestData](memo.WithAutoPersist[*TestData](path, time.Millisecond*10))
+	c.Set("key", &TestData{5}, time.Minute)
+
+	deadline := time.Now().Add(time.Second * 2)
+	for c.Stat().LastPersistAt.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 5)
+	}
+	c.Close()
+
+	info, err := os.Stat(path)
+	if err != nil || c.Stat().PersistedBytes != info.Size() {
+		t.Fatal(err)
+	}
+
+	restored := memo.New[*TestData](memo.WithRestoreOnStart[*TestData](path))
+	defer restored.Close()
+
+	if val, err := restored.Get("key"); err != nil || val.Value != 5 {
+		t.Fail()
+	}
+
+	missing := memo.New[*TestData](memo.WithRestoreOnStart[*TestData](path + ".missing"))
+	defer missing.Close()
+
+	if missing.Len() != 0 {
+		t.Fail()
+	}
+}
+
+func TestSetValueWithContext_LockWait(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	c.OnEvicted(func(key string, value *TestData) {
+		close(locked)
+		<-release
+	})
+
+	c.Set("expired", &TestData{5}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	go c.Get("expired")
+	<-locked
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	if err := c.SetWithContext(ctx, "key", &TestData{5}, time.Second*5); err == nil {
+		t.Fail()
+	}
+
+	close(release)
+}
+
+func TestRegistry(t *testing.T) {
+	r := memo.NewRegistry()
+	c := memo.New[*TestData]()
+
+	if err := memo.Register(r, "data", c); err != nil {
+		t.Fail()
+	}
+
+	if err := memo.Register(r, "data", c); err == nil {
+		t.Fail()
+	}
+
+	if got, err := memo.Get[*TestData](r, "data"); err != nil || got != c {
+		t.Fail()
+	}
+
+	if _, err := memo.Get[int](r, "data"); err == nil {
+		t.Fail()
+	}
+
+	if _, err := memo.Get[*TestData](r, "missing"); err == nil {
+		t.Fail()
+	}
+
+	if err := r.CloseAll(); err != nil {
+		t.Fail()
+	}
+
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	if ok, err := c.SetVersioned("key", &TestData{1}, time.Second*5, 0); !ok || err != nil {
+		t.Fail()
+	}
+
+	_, version, err := c.GetVersioned("key")
+	if err != nil {
+		t.Fail()
+	}
+
+	if ok, _ := c.SetVersioned("key", &TestData{2}, time.Second*5, 0); ok {
+		t.Fail()
+	}
+
+	if ok, _ := c.SetVersioned("key", &TestData{2}, time.Second*5, version); !ok {
+		t.Fail()
+	}
+
+	if ok, _ := c.SetVersioned("key", &TestData{3}, time.Second*5, version); ok {
+		t.Fail()
+	}
+
+	if val, newVersion, _ := c.GetVersioned("key"); val.Value != 2 || newVersion <= version {
+		t.Fail()
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key", &TestData{5}, time.Second*5)
+	if err := c.Delete("key"); err != nil {
+		t.Fail()
+	}
+
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestDeleteManyWithContext(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key1", &TestData{1}, time.Second*5)
+	c.Set("key2", &TestData{2}, time.Second*5)
+	c.Set("key3", &TestData{3}, time.Second*5)
+
+	n, err := c.DeleteManyWithContext(context.Background(), []string{"key1", "key2", "missing"})
+	if err != nil || n != 2 {
+		t.Fail()
+	}
+
+	if _, err := c.Get("key3"); err != nil {
+		t.Fail()
+	}
+}
+
+func TestDeleteManyWithReaders(t *testing.T) {
+	c := memo.New[int]()
+	defer c.Close()
+
+	for i := 0; i < 10000; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					c.Keys()
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.DeleteMany([]string{"2"})
+		c.Clear()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Error("DeleteMany and Clear starved by readers")
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestClearWithContext(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key1", &TestData{1}, time.Second*5)
+	c.Set("key2", &TestData{2}, time.Second*5)
+
+	if n, err := c.ClearWithContext(context.Background()); err != nil || n != 2 {
+		t.Fail()
+	}
+
+	if _, err := c.Get("key1"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestClearWithContext_Cancel(t *testing.T) {
+	c := memo.New[*TestData]()
+	c.Set("key", &TestData{5}, time.Second*5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if n, err := c.ClearWithContext(ctx); err == nil || n != 0 {
+		t.Fail()
+	}
+
+	if _, err := c.Get("key"); err != nil {
+		t.Fail()
+	}
+}
+
+func TestLoad(t *testing.T) {
+	calls := 0
+	loader := func(ctx context.Context, key string) (*TestData, error) {
+		calls++
+		return &TestData{5}, nil
+	}
+
+	c := memo.New[*TestData](memo.WithLoader[*TestData](loader, time.Second*5))
+
+	if val, err := c.Load(context.Background(), "key"); err != nil || val.Value != 5 {
+		t.Fail()
+	}
+
+	if _, err := c.Load(context.Background(), "key"); err != nil || calls != 1 {
+		t.Fail()
+	}
+}
+
+func TestTTLLoader(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	calls := 0
+	loader := func(ctx context.Context, key string) (int, time.Duration, error) {
+		calls++
+		if key == "nocache" {
+			return 1, 0, nil
+		}
+		return 2, time.Minute, nil
+	}
+
+	c := memo.New[int](memo.WithTTLLoader[int](loader, 0), memo.WithClock[int](clock))
+
+	for i := 0; i < 2; i++ {
+		if val, err := c.Load(context.Background(), "nocache"); err != nil || val != 1 {
+			t.Fail()
+		}
+	}
+
+	if calls != 2 || c.Has("nocache") {
+		t.Fail()
+	}
+
+	c.Load(context.Background(), "key")
+	clock.Advance(time.Second * 59)
+	if !c.Has("key") {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second * 2)
+	if c.Has("key") {
+		t.Fail()
+	}
+}
+
+func TestRefreshWorkers(t *testing.T) {
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (*TestData, error) {
+		<-release
+		return &TestData{10}, nil
+	}
+
+	c := memo.New[*TestData](
+		memo.WithLoader[*TestData](loader, time.Second*5),
+		memo.WithRefreshWorkers[*TestData](1),
+	)
+
+	c.Refresh("key1")
+	c.Refresh("key2")
+	time.Sleep(time.Millisecond * 10)
+
+	stat := c.Stat()
+	if stat.RefreshInFlight != 1 || stat.RefreshQueued != 1 {
+		t.Fail()
+	}
+
+	close(release)
+	time.Sleep(time.Millisecond * 10)
+
+	if val, err := c.Get("key2"); err != nil || val.Value != 10 {
+		t.Fail()
+	}
+}
+
+func TestGetStale_Refresh(t *testing.T) {
+	loader := func(ctx context.Context, key string) (*TestData, error) {
+		return &TestData{10}, nil
+	}
+
+	c := memo.New[*TestData](
+		memo.WithLoader[*TestData](loader, time.Second*5),
+		memo.WithStaleWindow[*TestData](time.Second*5),
+	)
+
+	c.Set("key", &TestData{5}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	if val, stale, _ := c.GetStale("key"); !stale || val.Value != 5 {
+		t.Fail()
+	}
+
+	time.Sleep(time.Millisecond * 10)
+
+	if val, err := c.Get("key"); err != nil || val.Value != 10 {
+		t.Fail()
+	}
+}
+
+func TestGetStale_MissAndCoalesce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (*TestData, error) {
+		calls.Add(1)
+		<-release
+		return &TestData{10}, nil
+	}
+
+	c := memo.New[*TestData](
+		memo.WithLoader[*TestData](loader, time.Second*5),
+		memo.WithStaleWindow[*TestData](time.Second*5),
+		memo.WithKeyTransform[*TestData](strings.ToLower),
+	)
+	defer c.Close()
+
+	c.Set("key", &TestData{5}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	if _, stale, _ := c.GetStale("KEY"); !stale {
+		t.Fail()
+	}
+
+	if _, stale, _ := c.GetStale("key"); !stale {
+		t.Fail()
+	}
+
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+
+	stat := c.Stat()
+	if calls.Load() != 1 || stat.Hits != 0 || stat.Misses != 2 {
+		t.Fail()
+	}
+}
+
+func TestRecomputeSize(t *testing.T) {
+	c := memo.New[string]()
+
+	c.Set("key1", "a", time.Second*5)
+	c.Set("key2", "abcdef", time.Second*5)
+	c.Set("key2", "abcdef", time.Second*5)
+
+	size := c.RecomputeSize()
+	if size != c.Stat().SizeBytes || size != int64(2*unsafe.Sizeof("")+7) {
+		t.Fail()
+	}
+}
+
+func TestLoadOrStore(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key", &TestData{1}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	if val, loaded, err := c.LoadOrStore("key", &TestData{2}, time.Second*5); err != nil || loaded || val.Value != 2 {
+		t.Fail()
+	}
+
+	if val, loaded, err := c.LoadOrStore("key", &TestData{3}, time.Second*5); err != nil || !loaded || val.Value != 2 {
+		t.Fail()
+	}
+}
+
+func TestInterface(t *testing.T) {
+	var c memo.Cache[*TestData] = memo.New[*TestData]()
+
+	c.Set("key", &TestData{5}, time.Second*5)
+	if !c.Has("key") || c.Has("missing") {
+		t.Fail()
+	}
+
+	c.Close()
+	if c.Has("key") {
+		t.Fail()
+	}
+}
+
+func TestOnEvictedBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := cache.New[*TestData](ctx, cancel)
+
+	batches := make(chan []cache.KV[*TestData], 10)
+	c.OnEvictedBatch(func(evicted []cache.KV[*TestData]) {
+		batches <- evicted
+	})
+	c.OnEvicted(func(key string, value *TestData) {
+		t.Fail()
+	})
+
+	c.Set("key1", &TestData{1}, time.Millisecond*1)
+	c.Set("key2", &TestData{2}, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	cache.StartClean(c, ctx, time.Millisecond*10)
+
+	select {
+	case evicted := <-batches:
+		if len(evicted) != 2 {
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fail()
+	}
+
+	c.Close()
+}
+
+func TestGetOrDefault(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+
+	c.Set("key", 5, time.Second*5)
+	c.Set("expired", 7, time.Second)
+	clock.Advance(time.Second * 2)
+
+	if c.GetOrDefault("key", 10) != 5 || c.GetOrDefault("missing", 10) != 10 || c.GetOrDefault("expired", 10) != 10 {
+		t.Fail()
+	}
+
+	if stat := c.Stat(); stat.Hits != 1 || stat.Misses != 2 {
+		t.Fail()
+	}
+}
+
+func TestDump(t *testing.T) {
+	c := memo.New[int]()
+
+	c.Set("key2", 2, time.Second*5)
+	c.Set("key1", 1, time.Second*5)
+	c.Set("expired", 3, time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	entries := c.Dump()
+	if len(entries) != 2 || entries[0].Key != "key1" || entries[1].Value != 2 {
+		t.Fail()
+	}
+
+	if entries[0].TTL <= 0 || entries[0].SizeBytes == 0 {
+		t.Fail()
+	}
+}
+
+func TestClosedPolicy(t *testing.T) {
+	ignore := memo.New[int](memo.WithClosedPolicy[int](memo.ClosedIgnore))
+	ignore.Close()
+
+	if err := ignore.Set("key", 5, time.Second*5); err != nil {
+		t.Fail()
+	}
+
+	panics := memo.New[int](memo.WithClosedPolicy[int](memo.ClosedPanic))
+	panics.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+
+	panics.Set("key", 5, time.Second*5)
+}
+
+func TestMaxWeight(t *testing.T) {
+	c := memo.New[int](memo.WithMaxWeight[int](10))
+
+	c.SetWithWeight("cheap", 1, time.Second*5, 2)
+	c.SetWithWeight("expensive", 2, time.Second*5, 8)
+	c.SetWithWeight("new", 3, time.Second*5, 2)
+
+	if c.Has("cheap") || !c.Has("expensive") || !c.Has("new") {
+		t.Fail()
+	}
+
+	if err := c.SetWithWeight("huge", 4, time.Second*5, 11); err == nil {
+		t.Fail()
+	}
+}
+
+func TestReset(t *testing.T) {
+	c := memo.New[*TestData]()
+
+	c.Set("key", &TestData{5}, time.Second*5)
+	c.Get("key")
+	c.Close()
+
+	c.Reset()
+
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+
+	if err := c.Set("key", &TestData{5}, time.Second*5); err != nil {
+		t.Fail()
+	}
+
+	if stat := c.Stat(); stat.Hits != 0 || stat.Misses != 1 {
+		t.Fail()
+	}
+}
+
+func TestReset_Stats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := memo.New[int](
+		memo.WithAsyncEviction[int](1, memo.QueueDrop),
+		memo.WithLockMetrics[int](1),
+		memo.WithAutoPersist[int](path, time.Millisecond*50),
+	)
+
+	release := make(chan struct{})
+	c.OnEvicted(func(key string, value int) {
+		<-release
+	})
+
+	for i := 0; i < 5; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+		c.Delete(strconv.Itoa(i))
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second * 2)
+	for c.Stat().LastPersistAt.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 5)
+	}
+	c.Close()
+
+	if st := c.Stat(); st.DroppedEvictions == 0 || st.LockWaitSamples == 0 || st.LastPersistAt.IsZero() {
+		t.Fatal(st)
+	}
+
+	c.Reset()
+	defer c.Close()
+
+	st := c.Stat()
+	if st.DroppedEvictions != 0 || st.LockWaitSamples != 0 || !st.LastPersistAt.IsZero() || st.PersistedBytes != 0 {
+		t.Fail()
+	}
+}
+
+func TestReset_AutoPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := memo.New[int](memo.WithAutoPersist[int](path, time.Millisecond*10))
+	defer c.Close()
+
+	c.Close()
+	c.Reset()
+	c.Set("key", 5, time.Minute)
+
+	deadline := time.Now().Add(time.Second * 2)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "key") {
+			return
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	t.Error("no snapshot written after Reset")
+}
+
+func TestReset_NewWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := memo.NewWithContext[*TestData](ctx)
+
+	c.Reset()
+	cancel()
+	time.Sleep(time.Millisecond * 10)
+
+	if err := c.Set("key", &TestData{5}, time.Second*5); err != nil {
+		t.Fail()
+	}
+}
+
+func TestMaxIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := cache.New[*TestData](ctx, cancel, cache.WithMaxIdle[*TestData](time.Millisecond*30))
+	cache.StartClean(c, ctx, time.Millisecond*5)
+
+	c.Set("active", &TestData{1}, time.Hour)
+	c.Set("idle", &TestData{2}, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		time.Sleep(time.Millisecond * 10)
+		if _, err := c.Get("active"); err != nil {
+			t.Fail()
+		}
+	}
+
+	if _, _, err := c.GetStale("idle"); err == nil {
+		t.Fail()
+	}
+
+	c.Close()
+}
+
+func TestMergeJSON(t *testing.T) {
+	src := memo.New[int]()
+	src.Set("short", 1, time.Second*1)
+	src.Set("long", 1, time.Hour)
+	src.Set("new", 1, time.Hour)
+
+	bytes, _ := src.MarshalJSON()
+
+	c := memo.New[int]()
+	c.Set("short", 2, time.Minute)
+	c.Set("long", 2, time.Minute)
+
+	if err := c.MergeJSON(bytes, memo.KeepNewer); err != nil {
+		t.Fail()
+	}
+
+	if c.GetOrDefault("short", 0) != 2 || c.GetOrDefault("long", 0) != 1 || c.GetOrDefault("new", 0) != 1 {
+		t.Fail()
+	}
+
+	if c.Stat().SizeBytes != c.RecomputeSize() {
+		t.Fail()
+	}
+
+	keep := memo.New[int]()
+	keep.Set("long", 2, time.Minute)
+	keep.MergeJSON(bytes, memo.KeepExisting)
+
+	if keep.GetOrDefault("long", 0) != 2 {
+		t.Fail()
+	}
+}
+
+func TestUnmarshal_Size(t *testing.T) {
+	c := memo.New[string]()
+	c.Set("key", "value", time.Second*5)
+	c.Set("expired", "value", time.Millisecond*1)
+	time.Sleep(time.Millisecond * 5)
+
+	bytes, _ := c.MarshalJSON()
+
+	uc := memo.New[string]()
+	if err := uc.UnmarshalJSON(bytes); err != nil {
+		t.Fail()
+	}
+
+	if uc.Has("expired") {
+		t.Fail()
+	}
+
+	if size := uc.Stat().SizeBytes; size == 0 || size != uc.RecomputeSize() {
+		t.Fail()
+	}
+}
+
+func TestOnCapacityEvict(t *testing.T) {
+	c := memo.New[int](memo.WithMaxWeight[int](2))
+
+	var demoted []string
+	c.OnCapacityEvict(func(key string, value int) error {
+		demoted = append(demoted, key)
+		return errors.New("store is down")
+	})
+	c.OnEvicted(func(key string, value int) {
+		t.Fail()
+	})
+
+	c.Set("key1", 1, time.Second*5)
+	c.Set("key2", 2, time.Second*5)
+	c.Set("key3", 3, time.Second*5)
+
+	if len(demoted) != 1 || c.Stat().CapacityEvictErrors != 1 {
+		t.Fail()
+	}
+}
+
+func TestClock(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[*TestData](memo.WithClock[*TestData](clock))
+
+	c.Set("key", &TestData{5}, time.Minute)
+	if _, err := c.Get("key"); err != nil {
+		t.Fail()
+	}
+
+	clock.Advance(time.Minute + time.Second)
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSizeUnderflow(t *testing.T) {
+	c := memo.New[string]()
+
+	c.Set("key1", "value", time.Second*5)
+	c.Set("key2", "value", time.Second*5)
+	c.Delete("key1")
+	c.Clear()
+
+	if stat := c.Stat(); stat.SizeBytes != 0 || stat.SizeUnderflows != 0 {
+		t.Fail()
+	}
+}
+
+func TestGetManyAndRefresh(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+
+	c.Set("key1", 1, time.Minute)
+	c.Set("key2", 2, time.Minute)
+	c.Set("expired", 3, time.Second)
+	clock.Advance(time.Second * 30)
+
+	found, missing := c.GetManyAndRefresh([]string{"key1", "expired", "missing"}, time.Minute)
+	if len(found) != 1 || found["key1"] != 1 || len(missing) != 2 {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second * 45)
+	if !c.Has("key1") || c.Has("key2") {
+		t.Fail()
+	}
+
+	if stat := c.Stat(); stat.Hits != 1 || stat.Misses != 2 {
+		t.Fail()
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithLogger[int](logger), memo.WithClock[int](clock))
+
+	c.Set("key", 1, time.Second)
+	clock.Advance(time.Second * 2)
+	c.Get("key")
+	c.Close()
+
+	if !strings.Contains(buf.String(), "reason=expired") || !strings.Contains(buf.String(), "cache closed") {
+		t.Fail()
+	}
+}
+
+func TestSweepLogSummary(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[string](
+		memo.WithLogger[string](logger),
+		memo.WithClock[string](clock),
+		memo.WithEvictionLogSampling[string](10),
+		memo.WithSizer(func(s string) int64 { return 4 }),
+	)
+	defer c.Close()
+
+	for i := 0; i < 25; i++ {
+		c.Set(strconv.Itoa(i), "v", time.Second)
+	}
+	clock.Advance(time.Second * 2)
+	cache.Clean(c)
+
+	out := buf.String()
+	if strings.Count(out, "reason=expired") != 3 {
+		t.Fail()
+	}
+
+	if strings.Count(out, "level=INFO msg=\"memo: sweep evicted entries\" entries=25 freed_bytes=100") != 1 {
+		t.Fail()
+	}
+
+	buf.Reset()
+	cache.Clean(c)
+	if buf.Len() != 0 {
+		t.Fail()
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	c := memo.New[string]()
+
+	c.Set("old", "value", time.Minute)
+	c.Set("kept", "value", time.Minute)
+
+	var evicted []string
+	c.OnEvicted(func(key string, value string) {
+		evicted = append(evicted, key)
+	})
+
+	if err := c.ReplaceAll(map[string]string{"kept": "new", "added": "new"}, time.Minute); err != nil {
+		t.Fail()
+	}
+
+	if c.Has("old") || c.GetOrDefault("kept", "") != "new" || !c.Has("added") {
+		t.Fail()
+	}
+
+	if len(evicted) != 1 || evicted[0] != "old" {
+		t.Fail()
+	}
+
+	if c.Stat().SizeBytes != c.RecomputeSize() {
+		t.Fail()
+	}
+}
+
+func TestReplaceAllRejected(t *testing.T) {
+	c := memo.New[int](memo.WithMaxKeyLength[int](3))
+	defer c.Close()
+
+	c.Set("old", 1, time.Minute)
+
+	err := c.ReplaceAll(map[string]int{"new": 2, "too long": 3}, time.Minute)
+	if !errors.Is(err, memo.ErrKeyTooLong) {
+		t.Fail()
+	}
+
+	if !c.Has("old") || c.Has("new") || c.Has("too long") {
+		t.Fail()
+	}
+}
+
+func TestTTLBounds(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithTTLBounds[int](time.Second, time.Minute), memo.WithClock[int](clock))
+
+	c.Set("short", 1, time.Nanosecond)
+	c.Set("long", 2, time.Hour)
+	c.Set("normal", 3, time.Second*30)
+
+	clock.Advance(time.Millisecond * 500)
+	if !c.Has("short") {
+		t.Fail()
+	}
+
+	clock.Advance(time.Minute)
+	if c.Has("long") {
+		t.Fail()
+	}
+
+	if c.Stat().ClampedTTLs != 2 {
+		t.Fail()
+	}
+}
+
+func TestLoaderCircuitBreaker(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	calls := 0
+	failing := true
+	loader := func(ctx context.Context, key string) (int, error) {
+		calls++
+		if failing {
+			return 0, errors.New("backend is down")
+		}
+		return 1, nil
+	}
+
+	c := memo.New[int](
+		memo.WithLoader[int](loader, time.Minute),
+		memo.WithLoaderCircuitBreaker[int](3, time.Minute, time.Second*30),
+		memo.WithClock[int](clock),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Load(context.Background(), "key"); err == nil {
+			t.Fail()
+		}
+	}
+
+	if _, err := c.Load(context.Background(), "key"); !errors.Is(err, memo.ErrLoaderCircuitOpen) {
+		t.Fail()
+	}
+
+	st := c.Stat()
+	if calls != 3 || !st.LoaderCircuitOpen || st.LoaderCircuitTrips != 1 || st.LoaderShortCircuits != 1 {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second * 31)
+	failing = false
+
+	val, err := c.Load(context.Background(), "key")
+	if err != nil || val != 1 || c.Stat().LoaderCircuitOpen {
+		t.Fail()
+	}
+}
+
+func TestGetOrSetSingleFlight(t *testing.T) {
+	c := memo.New[int]()
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := c.GetOrSet("key", time.Minute, func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 7, nil
+			})
+			if err != nil || val != 7 {
+				t.Fail()
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fail()
+	}
+}
+
+func TestGetOrSetStats(t *testing.T) {
+	c := memo.New[int]()
+	defer c.Close()
+
+	c.GetOrSet("key", time.Minute, func() (int, error) { return 1, nil })
+	c.GetOrSet("key", time.Minute, func() (int, error) { return 2, nil })
+
+	if st := c.Stat(); st.Misses != 1 || st.Hits != 1 {
+		t.Fail()
+	}
+}
+
+func TestGetOrSetDoesNotBlockOtherKeys(t *testing.T) {
+	c := memo.New[int]()
+	c.Set("other", 1, time.Minute)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go c.GetOrSet("slow", time.Minute, func() (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	<-started
+	defer close(release)
+
+	done := make(chan struct{})
+	go func() {
+		c.Get("other")
+		c.Set("another", 2, time.Minute)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fail()
+	}
+}
+
+func TestGetOrSetError(t *testing.T) {
+	c := memo.New[int]()
+
+	_, err := c.GetOrSet("key", time.Minute, func() (int, error) {
+		return 0, errors.New("failed")
+	})
+	if err == nil || c.Has("key") {
+		t.Fail()
+	}
+}
+
+func BenchmarkGetOrSetOtherKeys(b *testing.B) {
+	c := memo.New[int]()
+	c.Set("other", 1, time.Minute)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.GetOrSet(strconv.Itoa(i), time.Minute, func() (int, error) {
+				time.Sleep(time.Millisecond)
+				return i, nil
+			})
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Get("other")
+		}
+	})
+}
+
+func TestTTLHistogram(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+
+	c.Set("a", 1, time.Millisecond*500)
+	c.Set("b", 2, time.Second*30)
+	c.Set("c", 3, time.Second*45)
+	c.Set("d", 4, time.Hour*2)
+	c.Set("expired", 5, time.Millisecond)
+	clock.Advance(time.Millisecond * 10)
+
+	hist := c.TTLHistogram([]time.Duration{time.Hour, time.Second, time.Minute})
+	if len(hist) != 3 || hist[time.Second] != 1 || hist[time.Minute] != 2 || hist[time.Hour] != 0 {
+		t.Fail()
+	}
+}
+
+func TestSizer(t *testing.T) {
+	c := memo.New[string](memo.WithSizer(func(s string) int64 {
+		return int64(len(s)) * 10
+	}))
+
+	c.Set("a", "abc", time.Minute)
+	c.Set("b", "de", time.Minute)
+
+	if c.Stat().SizeBytes != 50 || c.RecomputeSize() != 50 {
+		t.Fail()
+	}
+
+	c.Delete("a")
+	if c.Stat().SizeBytes != 20 {
+		t.Fail()
+	}
+}
+
+func TestCleanRecoversCallbackPanic(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := cache.New[int](ctx, cancel, cache.WithClock[int](clock))
+	cache.StartClean(c, ctx, time.Millisecond*10)
+
+	var evicted atomic.Int64
+	c.OnEvicted(func(key string, value int) {
+		evicted.Add(1)
+		panic("bad callback")
+	})
+
+	c.Set("a", 1, time.Second)
+	c.Set("b", 2, time.Second)
+	clock.Advance(time.Second * 2)
+	time.Sleep(time.Millisecond * 50)
+
+	c.Set("c", 3, time.Second)
+	clock.Advance(time.Second * 2)
+	time.Sleep(time.Millisecond * 50)
+
+	if evicted.Load() != 3 || c.Stat().PanicsRecovered != 3 || len(c.Dump()) != 0 {
+		t.Fail()
+	}
+}
+
+func TestEvictFunc(t *testing.T) {
+	c := memo.New[int]()
+
+	var evicted []string
+	c.OnEvicted(func(key string, value int) {
+		evicted = append(evicted, key)
+	})
+
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+	}
+
+	n := c.EvictFunc(func(key string, value int, ttl time.Duration) bool {
+		return value%2 == 0 && ttl > 0
+	})
+
+	if n != 5 || len(evicted) != 5 || c.Has("4") || !c.Has("5") {
+		t.Fail()
+	}
+}
+
+func TestOverheadBytes(t *testing.T) {
+	c := memo.New[int]()
+
+	c.Set("a", 1, time.Minute)
+	one := c.Stat().OverheadBytes
+	c.Set("bb", 2, time.Minute)
+	two := c.Stat().OverheadBytes
+
+	if one <= 1 || two-2*one != 1 {
+		t.Fail()
+	}
+
+	c.Set("bb", 3, time.Minute)
+	c.Delete("bb")
+	if c.Stat().OverheadBytes != one {
+		t.Fail()
+	}
+}
+
+func TestAny(t *testing.T) {
+	c := memo.NewAny()
+	c.Set("name", "memo", time.Minute)
+	c.Set("port", 8080, time.Minute)
+	c.Set("data", TestData{Value: 1}, time.Minute)
+
+	if v, ok := c.Get("name"); !ok || v != "memo" {
+		t.Fail()
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fail()
+	}
+
+	if port, err := c.GetInt("port"); err != nil || port != 8080 {
+		t.Fail()
+	}
+
+	if _, err := c.GetString("port"); !errors.Is(err, memo.ErrTypeMismatch) {
+		t.Fail()
+	}
+
+	if data, err := memo.GetAs[TestData](c, "data"); err != nil || data.Value != 1 {
+		t.Fail()
+	}
+
+	if _, err := c.GetBool("missing"); err == nil || errors.Is(err, memo.ErrTypeMismatch) {
+		t.Fail()
+	}
+}
+
+func TestMaxConcurrentLoads(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	loader := func(ctx context.Context, key string) (int, error) {
+		started <- struct{}{}
+		<-release
+		return 1, nil
+	}
+
+	c := memo.New[int](
+		memo.WithLoader[int](loader, time.Minute),
+		memo.WithMaxConcurrentLoads[int](2, memo.BusyError),
+	)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Load(context.Background(), key)
+		}()
+	}
+	<-started
+	<-started
+
+	if c.Stat().LoadsInFlight != 2 {
+		t.Fail()
+	}
+
+	if _, err := c.Load(context.Background(), "c"); !errors.Is(err, memo.ErrLoadBusy) {
+		t.Fail()
+	}
+
+	close(release)
+	wg.Wait()
+
+	if v, err := c.Load(context.Background(), "c"); err != nil || v != 1 || c.Stat().LoadsInFlight != 0 {
+		t.Fail()
+	}
+}
+
+func TestCompression(t *testing.T) {
+	c := memo.New[string](memo.WithCompression[string](memo.GzipCompressor{}, 64))
+	big := strings.Repeat("memo ", 1000)
+
+	c.Set("big", big, time.Minute)
+	c.Set("small", "memo", time.Minute)
+
+	if v, err := c.Get("big"); err != nil || v != big {
+		t.Fail()
+	}
+
+	if v, err := c.Get("small"); err != nil || v != "memo" {
+		t.Fail()
+	}
+
+	if size := c.Stat().SizeBytes; size <= 0 || size >= int64(len(big)) {
+		t.Fail()
+	}
+
+	if c.Dump()[0].Value != big {
+		t.Fail()
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	c := memo.New[int]()
+	if err := c.Healthy(); err != nil {
+		t.Fail()
+	}
+
+	c.Close()
+	if err := c.Healthy(); err == nil {
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bare := cache.New[int](ctx, cancel)
+	if err := bare.Healthy(); err == nil {
+		t.Fail()
+	}
+
+	cleanCtx, stop := context.WithCancel(ctx)
+	cache.StartClean(bare, cleanCtx, time.Millisecond*10)
+	if err := bare.Healthy(); err != nil {
+		t.Fail()
+	}
+
+	stop()
+	time.Sleep(time.Millisecond * 20)
+	if err := bare.Healthy(); err == nil {
+		t.Fail()
+	}
+}
+
+func TestOnEvictedContext(t *testing.T) {
+	type traceKey struct{}
+
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+
+	var got []any
+	c.OnEvictedContext(func(ctx context.Context, key string, value int) {
+		got = append(got, ctx.Value(traceKey{}))
+	})
+
+	c.Set("a", 1, time.Second)
+	c.Set("b", 2, time.Second)
+	clock.Advance(time.Second * 2)
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+	c.GetWithContext(ctx, "a")
+	c.Get("b")
+
+	if len(got) != 2 || got[0] != "trace-1" || got[1] != nil {
+		t.Fail()
+	}
+}
+
+func TestRefreshMatching(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+
+	c.Set("config:a", 1, time.Second)
+	c.Set("config:b", 2, time.Second)
+	c.Set("user:a", 3, time.Second)
+	c.Set("config:old", 4, time.Millisecond)
+	clock.Advance(time.Millisecond * 10)
+
+	n := c.RefreshMatching(func(key string) bool {
+		return strings.HasPrefix(key, "config:")
+	}, time.Hour)
+	clock.Advance(time.Minute)
+
+	if n != 2 || !c.Has("config:a") || !c.Has("config:b") || c.Has("user:a") || c.Has("config:old") {
+		t.Fail()
+	}
+}
+
+func TestDrainOnClose(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	drained := make(map[string]int)
+	c := memo.New[int](
+		memo.WithClock[int](clock),
+		memo.WithDrainOnClose(func(key string, value int) {
+			drained[key] = value
+		}),
+	)
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	c.Set("expired", 3, time.Second)
+	clock.Advance(time.Second * 2)
+
+	c.Close()
+	if len(drained) != 2 || drained["a"] != 1 || drained["b"] != 2 {
+		t.Fail()
+	}
+}
+
+func TestLoaderRetry(t *testing.T) {
+	var calls atomic.Int64
+	loader := func(ctx context.Context, key string) (int, error) {
+		if calls.Add(1) < 3 {
+			return 0, errors.New("blip")
+		}
+		return 1, nil
+	}
+
+	c := memo.New[int](
+		memo.WithLoader[int](loader, time.Minute),
+		memo.WithLoaderRetry[int](3, time.Millisecond),
+	)
+
+	if v, err := c.Load(context.Background(), "key"); err != nil || v != 1 || calls.Load() != 3 {
+		t.Fail()
+	}
+}
+
+func TestLoaderRetryCancel(t *testing.T) {
+	loader := func(ctx context.Context, key string) (int, error) {
+		return 0, errors.New("down")
+	}
+
+	c := memo.New[int](
+		memo.WithLoader[int](loader, time.Minute),
+		memo.WithLoaderRetry[int](5, time.Hour),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.Load(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fail()
+	}
+}
+
+func TestTypedStore(t *testing.T) {
+	type UserID int
+
+	users := memo.NewTypedStore[UserID, string](func(id UserID) string {
+		return "user:" + strconv.Itoa(int(id))
+	})
+
+	users.Set(UserID(1), "alice", time.Minute)
+	if v, err := users.Get(UserID(1)); err != nil || v != "alice" {
+		t.Fail()
+	}
+
+	if !users.Cache().Has("user:1") || users.Has(UserID(2)) {
+		t.Fail()
+	}
+
+	users.Delete(UserID(1))
+	if users.Has(UserID(1)) {
+		t.Fail()
+	}
+}
+
+func TestEvictionCallbackTimeout(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](
+		memo.WithClock[int](clock),
+		memo.WithEvictionCallbackTimeout[int](time.Millisecond*20),
+	)
+
+	release := make(chan struct{})
+	defer close(release)
+	c.OnEvicted(func(key string, value int) {
+		<-release
+	})
+
+	c.Set("key", 1, time.Second)
+	clock.Advance(time.Second * 2)
+
+	start := time.Now()
+	c.Get("key")
+	if time.Since(start) > time.Second || c.Stat().CallbackTimeouts != 1 {
+		t.Fail()
+	}
+}
+
+func TestView(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+	c.Set("b", 2, time.Minute)
+	c.Set("a", 1, time.Minute)
+	c.Set("expired", 3, time.Millisecond)
+	clock.Advance(time.Second)
+
+	err := c.View(func(r memo.ReadView[int]) {
+		if v, err := r.Get("a"); err != nil || v != 1 {
+			t.Fail()
+		}
+
+		if _, err := r.Get("expired"); err == nil || r.Has("expired") {
+			t.Fail()
+		}
+
+		if keys := r.Keys(); len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+			t.Fail()
+		}
+	})
+	if err != nil {
+		t.Fail()
+	}
+
+	c.Close()
+	if err := c.View(func(r memo.ReadView[int]) {}); err == nil {
+		t.Fail()
+	}
+}
+
+func TestByteCache(t *testing.T) {
+	c := memo.NewByteCache()
+
+	value := []byte("hello")
+	c.Set("a", value, time.Minute)
+	c.Set("b", []byte("world!"), time.Minute)
+	value[0] = 'j'
+
+	ref, err := c.GetRef("a")
+	if err != nil || string(ref) != "hello" || cap(ref) != len(ref) {
+		t.Fail()
+	}
+
+	cp, _ := c.GetCopy("a")
+	cp[0] = 'y'
+	if ref, _ := c.GetRef("a"); string(ref) != "hello" {
+		t.Fail()
+	}
+
+	if c.Stat().SizeBytes != 64<<10 || c.Cache().Stat().SizeBytes != 11 {
+		t.Fail()
+	}
+
+	big := bytes.Repeat([]byte("x"), 100<<10)
+	c.Set("big", big, time.Minute)
+	if ref, _ := c.GetRef("big"); !bytes.Equal(ref, big) || c.Stat().SizeBytes != 64<<10+100<<10 {
+		t.Fail()
+	}
+}
+
+func TestByteCache_RetainedSlabs(t *testing.T) {
+	c := memo.NewByteCache()
+	defer c.Close()
+
+	value := bytes.Repeat([]byte("x"), 100)
+	for i := 0; i < 1000; i++ {
+		c.Set(strconv.Itoa(i), value, time.Minute)
+	}
+
+	if c.Stat().SizeBytes != 2*64<<10 {
+		t.Fail()
+	}
+
+	for i := 1; i < 1000; i++ {
+		c.Delete(strconv.Itoa(i))
+	}
+
+	if c.Stat().SizeBytes != 64<<10 {
+		t.Fail()
+	}
+
+	c.Delete("0")
+	if c.Stat().SizeBytes != 0 {
+		t.Fail()
+	}
+}
+
+func TestStatsDisabled(t *testing.T) {
+	c := memo.New[int](memo.WithStatsDisabled[int]())
+	c.Set("key", 1, time.Minute)
+	c.Get("key")
+	c.Get("missing")
+
+	st := c.Stat()
+	if !st.StatsDisabled || st.Hits != 0 || st.Misses != 0 || st.HitRate != 0 || st.SizeBytes == 0 {
+		t.Fail()
+	}
+}
+
+func TestConcurrentStats(t *testing.T) {
+	c := memo.New[int]()
+	c.Set("key", 1, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Get("key")
+				c.Get("missing")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if st := c.Stat(); st.Hits != 8000 || st.Misses != 8000 {
+		t.Fail()
+	}
+}
+
+func TestSetExpireAt(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+
+	at := clock.Now().Add(time.Minute)
+	c.SetExpireAt("key", 1, at)
+	if c.Dump()[0].ExpiresAt != at {
+		t.Fail()
+	}
+
+	clock.Advance(time.Minute + time.Nanosecond)
+	if c.Has("key") {
+		t.Fail()
+	}
+
+	if err := c.SetExpireAt("past", 1, clock.Now().Add(-time.Second)); err != nil || c.Has("past") {
+		t.Fail()
+	}
+
+	strict := memo.New[int](memo.WithRejectPastExpireAt[int]())
+	if err := strict.SetExpireAt("past", 1, time.Now().Add(-time.Second)); err == nil {
+		t.Fail()
+	}
+}
+
+func TestStatsStream(t *testing.T) {
+	c := memo.New[int]()
+	c.Set("key", 1, time.Minute)
+	c.Get("key")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := c.StatsStream(ctx, time.Millisecond*5)
+
+	for i := 0; i < 3; i++ {
+		if st := <-stream; st.Hits != 1 {
+			t.Fail()
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-stream:
+		if ok {
+			if _, ok := <-stream; ok {
+				t.Fail()
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fail()
+	}
+}
+
+func TestLazyCleanStrategy(t *testing.T) {
+	before := runtime.NumGoroutine()
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[string](
+		memo.WithClock[string](clock),
+		memo.WithCleanStrategy[string](memo.StrategyLazy),
+		memo.WithSizer(func(s string) int64 { return 10 }),
+	)
+
+	if runtime.NumGoroutine() > before || c.Healthy() != nil {
+		t.Fail()
+	}
+
+	c.Set("a", "1", time.Minute)
+	c.Set("b", "2", time.Second)
+	c.Set("c", "3", time.Minute)
+	clock.Advance(time.Second * 2)
+
+	keys := c.Keys()
+	if c.Len() != 2 || len(keys) != 2 || keys[0] != "a" || keys[1] != "c" || c.Stat().SizeBytes != 20 {
+		t.Fail()
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	c := memo.New[int](memo.WithDefaultTimeout[int](time.Millisecond * 20))
+	c.Set("key", 1, time.Minute)
+
+	c.View(func(r memo.ReadView[int]) {
+		if err := c.Set("key", 2, time.Minute); !errors.Is(err, memo.ErrTimeout) {
+			t.Fail()
+		}
+
+		if err := c.Delete("key"); !errors.Is(err, memo.ErrTimeout) {
+			t.Fail()
+		}
+
+		if v, err := c.Get("key"); err != nil || v != 1 {
+			t.Fail()
+		}
+
+		start := time.Now()
+		if _, _, err := c.SetOrGet("other", 1, time.Minute); !errors.Is(err, memo.ErrTimeout) {
+			t.Fail()
+		}
+		if _, err := c.SetVersioned("other", 1, time.Minute, 0); !errors.Is(err, memo.ErrTimeout) {
+			t.Fail()
+		}
+		if err := c.ReplaceAll(map[string]int{"other": 1}, time.Minute); !errors.Is(err, memo.ErrTimeout) {
+			t.Fail()
+		}
+		if err := c.Pin("key"); !errors.Is(err, memo.ErrTimeout) {
+			t.Fail()
+		}
+		if c.TrimTo(0) != 0 {
+			t.Fail()
+		}
+		if time.Since(start) > time.Millisecond*500 {
+			t.Fail()
+		}
+	})
+
+	if err := c.Set("key", 2, time.Minute); err != nil {
+		t.Fail()
+	}
+}
+
+func TestIndex(t *testing.T) {
+	type User struct {
+		ID    int
+		Email string
+	}
+
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[User](memo.WithClock[User](clock))
+	c.Set("1", User{1, "a@example.com"}, time.Minute)
+
+	if err := c.AddIndex("email", func(u User) string { return u.Email }); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set("2", User{2, "b@example.com"}, time.Minute)
+	c.Set("3", User{3, "a@example.com"}, time.Second)
+
+	users, err := c.GetByIndex("email", "a@example.com")
+	if err != nil || len(users) != 2 || users[0].ID != 1 || users[1].ID != 3 {
+		t.Fail()
+	}
+
+	c.Set("1", User{1, "c@example.com"}, time.Minute)
+	clock.Advance(time.Second * 2)
+	if users, _ := c.GetByIndex("email", "a@example.com"); len(users) != 0 {
+		t.Fail()
+	}
+
+	c.Delete("2")
+	if users, _ := c.GetByIndex("email", "b@example.com"); len(users) != 0 {
+		t.Fail()
+	}
+
+	if _, err := c.GetByIndex("missing", "x"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestOnSet(t *testing.T) {
+	c := memo.New[int](memo.WithTTLBounds[int](0, time.Minute))
+	defer c.Close()
+
+	var keys []string
+	c.OnSet(func(key string, value int, ttl time.Duration) {
+		if !c.Has(key) || ttl != time.Minute {
+			t.Fail()
+		}
+		keys = append(keys, key)
+	})
+
+	c.Set("key1", 1, time.Hour)
+	c.SetWithContext(context.Background(), "key2", 2, time.Minute)
+	c.ReplaceAll(map[string]int{"key3": 3}, time.Minute)
+
+	if len(keys) != 3 || keys[2] != "key3" {
+		t.Fail()
+	}
+
+	c.Close()
+	c.Set("key4", 4, time.Minute)
+	if len(keys) != 3 {
+		t.Fail()
+	}
+}
+
+func TestOnSetOtherWrites(t *testing.T) {
+	c := memo.New[int]()
+	defer c.Close()
+
+	var keys []string
+	c.OnSet(func(key string, value int, ttl time.Duration) {
+		if !c.Has(key) {
+			t.Fail()
+		}
+		keys = append(keys, key)
+	})
+
+	c.SetOrGet("key1", 1, time.Minute)
+	c.LoadOrStore("key1", 2, time.Minute)
+	c.SetVersioned("key2", 2, time.Minute, 0)
+
+	src := memo.New[int]()
+	defer src.Close()
+	src.Set("key3", 3, time.Minute)
+	data, _ := src.MarshalJSON()
+
+	c.MergeJSON(data, memo.Overwrite)
+	c.UnmarshalJSON(data)
+
+	if strings.Join(keys, ",") != "key1,key2,key3,key3" {
+		t.Fail()
+	}
+}
+
+func TestMaxKeyLength(t *testing.T) {
+	c := memo.New[int](memo.WithMaxKeyLength[int](4))
+	defer c.Close()
+
+	if err := c.Set("key1", 1, time.Minute); err != nil {
+		t.Fail()
+	}
+
+	if err := c.Set("key12", 2, time.Minute); !errors.Is(err, memo.ErrKeyTooLong) {
+		t.Fail()
+	}
+
+	if c.Has("key12") || c.Len() != 1 || c.Stat().RejectedSets != 1 {
+		t.Fail()
+	}
+}
+
+func TestCleanExpiryBuckets(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock), memo.WithStaleWindow[int](time.Second))
+	defer c.Close()
+
+	c.Set("short", 1, time.Second)
+	c.Set("long", 2, time.Hour)
+	c.Set("replaced", 3, time.Second)
+	c.Set("replaced", 3, time.Hour)
+
+	clock.Advance(time.Second + time.Millisecond)
+	cache.Clean(c)
+	if _, _, err := c.GetStale("short"); err != nil {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second)
+	cache.Clean(c)
+	if len(c.Dump()) != 2 || !c.Has("long") || !c.Has("replaced") || c.Stat().Evictions != 1 {
+		t.Fail()
+	}
+
+	clock.Advance(time.Hour * 2)
+	cache.Clean(c)
+	if len(c.Dump()) != 0 {
+		t.Fail()
+	}
+}
+
+func benchmarkClean(b *testing.B, opts ...memo.Option[int]) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](append(opts, memo.WithClock[int](clock))...)
+	defer c.Close()
+
+	for i := 0; i < 1000000; i++ {
+		c.Set("long"+strconv.Itoa(i), i, time.Hour*24*365)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for j := 0; j < 10000; j++ {
+			c.Set("short"+strconv.Itoa(j), j, time.Second)
+		}
+		clock.Advance(time.Second * 2)
+		b.StartTimer()
+
+		cache.Clean(c)
+	}
+}
+
+func BenchmarkCleanExpiryBuckets(b *testing.B) {
+	benchmarkClean(b)
+}
+
+func BenchmarkCleanFullScan(b *testing.B) {
+	benchmarkClean(b, memo.WithMaxIdle[int](time.Hour*24*365))
+}
+
+func TestStatsJSON(t *testing.T) {
+	c := memo.New[int](memo.WithClock[int](memotest.NewClock(time.Now())))
+	defer c.Close()
+
+	c.Set("key", 1, time.Minute)
+	c.Get("key")
+	c.Get("missing")
+
+	data, err := json.Marshal(c.Stat())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(data, []byte(`"hits":1,"misses":1`)) || !bytes.Contains(data, []byte(`"hit_rate":50`)) {
+		t.Fail()
+	}
+
+	if !strings.HasPrefix(c.Stat().String(), "hits=1 misses=1 hit_rate=50.00") {
+		t.Fail()
+	}
+}
+
+func TestNewBare(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := memo.NewBare[int]()
+
+	if runtime.NumGoroutine() > before {
+		t.Fail()
+	}
+
+	c.Set("key", 1, time.Minute)
+	if val, err := c.Get("key"); err != nil || val != 1 || c.Healthy() != nil {
+		t.Fail()
+	}
+
+	if err := c.Close(); err != nil || c.Has("key") {
+		t.Fail()
+	}
+}
+
+func TestNewBare_Workers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	before := runtime.NumGoroutine()
+	c := memo.NewBare[int](
+		memo.WithRefreshWorkers[int](4),
+		memo.WithAutoPersist[int](path, time.Millisecond*10),
+	)
+
+	c.Set("key", 1, time.Minute)
+
+	deadline := time.Now().Add(time.Second * 2)
+	for c.Stat().LastPersistAt.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	if c.Stat().LastPersistAt.IsZero() {
+		t.Fail()
+	}
+
+	c.Close()
+
+	deadline = time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	if runtime.NumGoroutine() > before {
+		t.Fail()
+	}
+}
+
+func TestGetOrdered(t *testing.T) {
+	c := memo.New[int]()
+	defer c.Close()
+
+	c.Set("key1", 1, time.Minute)
+	c.Set("key3", 3, time.Minute)
+	c.Set("expired", 4, -time.Second)
+
+	values, found := c.GetOrdered([]string{"key3", "key2", "key1", "expired"})
+	if len(values) != 4 || values[0] != 3 || values[1] != 0 || values[2] != 1 || values[3] != 0 {
+		t.Fail()
+	}
+
+	if !found[0] || found[1] || !found[2] || found[3] {
+		t.Fail()
+	}
+
+	st := c.Stat()
+	if st.Hits != 2 || st.Misses != 2 {
+		t.Fail()
+	}
+}
+
+func TestDebugHandler(t *testing.T) {
+	c := memo.New[any]()
+	defer c.Close()
+
+	c.Set("key", 1, time.Minute)
+	c.Set("func", func() {}, time.Minute)
+
+	h := c.DebugHandler()
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	if rec := get("/debug/cache/get?key=key"); rec.Code != http.StatusOK || rec.Body.String() != "1" {
+		t.Fail()
+	}
+
+	if rec := get("/debug/cache/keys"); rec.Body.String() != `["func","key"]` {
+		t.Fail()
+	}
+
+	if rec := get("/debug/cache/stats"); !strings.Contains(rec.Body.String(), `"hits":0`) {
+		t.Fail()
+	}
+
+	if rec := get("/debug/cache/get?key=func"); rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "func") {
+		t.Fail()
+	}
+
+	if rec := get("/debug/cache/get?key=missing"); rec.Code != http.StatusNotFound {
+		t.Fail()
+	}
+}
+
+func TestAsyncEviction(t *testing.T) {
+	c := memo.New[int](memo.WithAsyncEviction[int](100, memo.QueueBlock))
+
+	var evicted []string
+	c.OnEvicted(func(key string, value int) {
+		c.Has(key)
+		evicted = append(evicted, key)
+	})
+
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+	}
+	for i := 0; i < 10; i++ {
+		c.Delete(strconv.Itoa(i))
+	}
+	c.Close()
+
+	if len(evicted) != 10 {
+		t.Fatal(evicted)
+	}
+
+	for i, key := range evicted {
+		if key != strconv.Itoa(i) {
+			t.Fail()
+		}
+	}
+}
+
+func TestAsyncEvictionDrop(t *testing.T) {
+	c := memo.New[int](memo.WithAsyncEviction[int](1, memo.QueueDrop))
+
+	release := make(chan struct{})
+	c.OnEvicted(func(key string, value int) {
+		<-release
+	})
+
+	for i := 0; i < 5; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+		c.Delete(strconv.Itoa(i))
+	}
+
+	if c.Stat().DroppedEvictions == 0 {
+		t.Fail()
+	}
+
+	close(release)
+	c.Close()
+}
+
+func TestAsyncEvictionContextDone(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := memo.NewWithContext[int](ctx, memo.WithAsyncEviction[int](10, memo.QueueBlock))
+	c.Set("key", 1, time.Minute)
+	cancel()
+
+	deadline := time.Now().Add(time.Second * 2)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	if !c.Closed() || runtime.NumGoroutine() > before {
+		t.Fail()
+	}
+}
+
+func TestKey(t *testing.T) {
+	if memo.Key("a", "bc") == memo.Key("ab", "c") || memo.Key("a:", "b") == memo.Key("a", ":b") {
+		t.Fail()
+	}
+
+	if memo.Key("a", "bc") != "1:a2:bc" || memo.Key() != "" || memo.Key("") != "0:" {
+		t.Fail()
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	c := memo.New[int](memo.WithMaxKeyLength[int](4))
+
+	errs := c.SetMany(map[string]int{"key1": 1, "key2": 2, "key123": 3}, time.Minute)
+	if len(errs) != 1 || !errors.Is(errs["key123"], memo.ErrKeyTooLong) {
+		t.Fail()
+	}
+
+	if !c.Has("key1") || !c.Has("key2") || c.Has("key123") {
+		t.Fail()
+	}
+
+	if errs := c.SetMany(map[string]int{"key3": 3}, time.Minute); errs != nil {
+		t.Fail()
+	}
+
+	c.Close()
+	if errs := c.SetMany(map[string]int{"key4": 4}, time.Minute); errs["key4"] == nil {
+		t.Fail()
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	lower := memo.WithKeyTransform[int](strings.ToLower)
+	items := map[string]int{"KEY": 1, "key": 1}
+	lines := `{"key":"a","value":1,"ttl":"2100-01-01T00:00:00Z"}
+{"key":"a","value":2,"ttl":"2100-01-01T00:00:00Z"}
+`
+
+	c := memo.New[int](lower)
+	if errs := c.SetMany(items, time.Minute); errs != nil || c.Len() != 1 {
+		t.Fail()
+	}
+
+	if err := c.UnmarshalNDJSON(strings.NewReader(lines)); err != nil {
+		t.Fail()
+	}
+
+	if val, _ := c.Get("a"); val != 2 {
+		t.Fail()
+	}
+
+	strict := memo.New[int](lower, memo.WithDuplicateKeys[int](memo.DuplicateError))
+	errs := strict.SetMany(items, time.Minute)
+	if len(errs) != 1 || strict.Len() != 1 {
+		t.Fail()
+	}
+
+	for _, err := range errs {
+		if !errors.Is(err, memo.ErrDuplicateKey) {
+			t.Fail()
+		}
+	}
+
+	err := strict.UnmarshalNDJSON(strings.NewReader(lines))
+	if !errors.Is(err, memo.ErrDuplicateKey) || !strings.Contains(err.Error(), "line 2") {
+		t.Fail()
+	}
+
+	if val, _ := strict.Get("a"); val != 1 {
+		t.Fail()
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	c := memo.New[[]byte]()
+	defer c.Close()
+
+	var h interface {
+		Get(key string) ([]byte, bool)
+		Set(key string, value []byte)
+		Delete(key string)
+	} = memoadapter.NewHTTPCache(c, time.Minute)
+
+	h.Set("key", []byte("value"))
+	if val, ok := h.Get("key"); !ok || string(val) != "value" {
+		t.Fail()
+	}
+	h.Delete("key")
+	if _, ok := h.Get("key"); ok {
+		t.Fail()
+	}
+
+	b := memoadapter.NewByteKeys(c, time.Minute)
+	b.Set([]byte("key"), []byte("value"))
+	if val, ok := b.Get([]byte("key")); !ok || string(val) != "value" || !c.Has("key") {
+		t.Fail()
+	}
+	b.Delete([]byte("key"))
+	if _, ok := b.Get([]byte("key")); ok {
+		t.Fail()
+	}
+}
+
+func TestCloneOnGet(t *testing.T) {
+	c := memo.New[*TestData](memo.WithCloneOnGet(func(v *TestData) *TestData {
+		cp := *v
+		return &cp
+	}))
+	defer c.Close()
+
+	c.Set("key", &TestData{5}, time.Minute)
+
+	val, _ := c.Get("key")
+	val.Value = 10
+
+	if val, _ := c.Get("key"); val.Value != 5 {
+		t.Fail()
+	}
+
+	values, _ := c.GetOrdered([]string{"key"})
+	values[0].Value = 10
+	if val, _ := c.Get("key"); val.Value != 5 {
+		t.Fail()
+	}
+}
+
+func TestLockMetrics(t *testing.T) {
+	c := memo.New[int](memo.WithLockMetrics[int](2))
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		c.Set("key", i, time.Minute)
+		c.Get("key")
+	}
+
+	st := c.Stat()
+	if st.LockWaitSamples != 10 {
+		t.Fail()
+	}
+
+	for i := 0; i < 10; i++ {
+		c.Has("key")
+		c.SetOrGet("key", i, time.Minute)
+	}
+	if c.Stat().LockWaitSamples != 20 {
+		t.Fail()
+	}
+	if st.LockWaitAvg < 0 || st.LockWaitP99 < st.LockWaitAvg/2 {
+		t.Fail()
+	}
+
+	off := memo.New[int]()
+	defer off.Close()
+	off.Set("key", 1, time.Minute)
+	if off.Stat().LockWaitSamples != 0 {
+		t.Fail()
+	}
+}
+
+func TestGetOrLoadMany(t *testing.T) {
+	c := memo.New[int]()
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+
+	var calls int
+	loader := func(missing []string) (map[string]int, error) {
+		calls++
+		if len(missing) != 2 || missing[0] != "b" || missing[1] != "c" {
+			t.Fail()
+		}
+		return map[string]int{"b": 2}, nil
+	}
+
+	got, err := c.GetOrLoadMany([]string{"a", "b", "c", "b"}, time.Minute, loader)
+	if err != nil || calls != 1 || len(got) != 2 || got["a"] != 1 || got["b"] != 2 {
+		t.Fail()
+	}
+	if val, err := c.Get("b"); err != nil || val != 2 {
+		t.Fail()
+	}
+	if _, err := c.Get("c"); err == nil {
+		t.Fail()
+	}
+
+	got, err = c.GetOrLoadMany([]string{"a", "b"}, time.Minute, func([]string) (map[string]int, error) {
+		t.Fail()
+		return nil, nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Fail()
+	}
+
+	loadErr := errors.New("db down")
+	got, err = c.GetOrLoadMany([]string{"a", "d"}, time.Minute, func([]string) (map[string]int, error) {
+		return nil, loadErr
+	})
+	if !errors.Is(err, loadErr) || len(got) != 1 || got["a"] != 1 {
+		t.Fail()
+	}
+}
+
+func TestValueEquals(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](
+		memo.WithClock[int](clock),
+		memo.WithValueEquals(func(a, b int) bool { return a == b }),
+	)
+	defer c.Close()
+
+	var sets int
+	c.OnSet(func(string, int, time.Duration) { sets++ })
+
+	c.Set("key", 1, time.Minute)
+	clock.Advance(time.Second * 50)
+	c.Set("key", 1, time.Minute)
+	if sets != 1 {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second * 50)
+	if val, err := c.Get("key"); err != nil || val != 1 {
+		t.Fail()
+	}
+
+	c.Set("key", 2, time.Minute)
+	if val, _ := c.Get("key"); val != 2 || sets != 2 {
+		t.Fail()
+	}
+}
+
+func TestGrowthAlert(t *testing.T) {
+	var alerts []int
+	c := memo.New[int](memo.WithGrowthAlert[int](2, func(count int) {
+		alerts = append(alerts, count)
+	}))
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	if len(alerts) != 0 {
+		t.Fail()
+	}
+
+	c.Set("c", 3, time.Minute)
+	c.Set("d", 4, time.Minute)
+	if len(alerts) != 1 || alerts[0] != 3 {
+		t.Fail()
+	}
+
+	c.Delete("c")
+	c.Delete("d")
+	c.Set("a", 5, time.Minute)
+	c.Set("e", 6, time.Minute)
+	if len(alerts) != 2 || alerts[1] != 3 {
+		t.Fail()
+	}
+
+	c.Delete("e")
+	c.Set("f", 7, time.Minute)
+	if len(alerts) != 3 || alerts[2] != 3 {
+		t.Fail()
+	}
+}
+
+func TestGrowthAlertLiveCount(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	var alerts []int
+	c := memo.New[int](
+		memo.WithClock[int](clock),
+		memo.WithGrowthAlert[int](2, func(count int) {
+			alerts = append(alerts, count)
+		}),
+	)
+	defer c.Close()
+
+	c.Set("a", 1, time.Second)
+	c.Set("b", 2, time.Second)
+	clock.Advance(time.Second * 2)
+
+	c.Set("c", 3, time.Minute)
+	if len(alerts) != 0 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,263 @@
+package test
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crewcrew23/memo/pkg/memo"
+	"github.com/crewcrew23/memo/pkg/memotest"
+)
+
+func TestEvictLRU(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithMaxWeight[int](2), memo.WithClock[int](clock))
+
+	c.Set("key1", 1, time.Minute)
+	clock.Advance(time.Second)
+	c.Set("key2", 2, time.Minute)
+	clock.Advance(time.Second)
+	c.Get("key1")
+	clock.Advance(time.Second)
+	c.Set("key3", 3, time.Minute)
+
+	if !c.Has("key1") || c.Has("key2") || !c.Has("key3") {
+		t.Fail()
+	}
+}
+
+func TestNegativeWeight(t *testing.T) {
+	c := memo.New[int](memo.WithMaxWeight[int](10))
+	defer c.Close()
+
+	if err := c.SetWithWeight("neg", 1, time.Minute, -1000); err == nil {
+		t.Fail()
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+	}
+
+	if c.Has("neg") || c.Len() != 10 {
+		t.Fail()
+	}
+}
+
+func TestEvictionSampleSize(t *testing.T) {
+	c := memo.New[int](memo.WithMaxWeight[int](10), memo.WithEvictionSampleSize[int](3))
+
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+	}
+
+	if len(c.Dump()) != 10 || !c.Has("99") {
+		t.Fail()
+	}
+}
+
+func benchmarkEviction(b *testing.B, opts ...memo.Option[int]) {
+	c := memo.New[int](append(opts, memo.WithMaxWeight[int](10000))...)
+	defer c.Close()
+
+	for i := 0; i < 10000; i++ {
+		c.Set(strconv.Itoa(i), i, time.Hour)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(strconv.Itoa(10000+i), i, time.Hour)
+	}
+}
+
+func BenchmarkEvictionExact(b *testing.B) {
+	benchmarkEviction(b)
+}
+
+func BenchmarkEvictionSampled(b *testing.B) {
+	benchmarkEviction(b, memo.WithEvictionSampleSize[int](5))
+}
+
+func TestMaxEntriesExpiredFirst(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithMaxEntries[int](3), memo.WithClock[int](clock))
+
+	c.Set("old", 1, time.Hour)
+	clock.Advance(time.Second)
+	c.Set("short1", 2, time.Second)
+	c.Set("short2", 3, time.Second)
+	clock.Advance(time.Second * 2)
+
+	c.Set("new", 4, time.Hour)
+	if !c.Has("old") || !c.Has("new") || len(c.Dump()) != 2 {
+		t.Fail()
+	}
+
+	if c.Stat().Evictions != 2 {
+		t.Fail()
+	}
+}
+
+func TestMaxEntriesLRU(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithMaxEntries[int](2), memo.WithClock[int](clock))
+
+	c.Set("key1", 1, time.Minute)
+	clock.Advance(time.Second)
+	c.Set("key2", 2, time.Minute)
+	clock.Advance(time.Second)
+	c.Get("key1")
+	clock.Advance(time.Second)
+	c.Set("key3", 3, time.Minute)
+
+	if !c.Has("key1") || c.Has("key2") || !c.Has("key3") {
+		t.Fail()
+	}
+}
+
+func TestTrimTo(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[string](
+		memo.WithClock[string](clock),
+		memo.WithSizer(func(s string) int64 { return 10 }),
+	)
+
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), "v", time.Minute)
+		clock.Advance(time.Second)
+	}
+	c.Set("expired", "v", time.Millisecond)
+	clock.Advance(time.Second)
+
+	n := c.TrimTo(50)
+	if n != 6 || c.Stat().SizeBytes != 50 || c.Has("0") || !c.Has("9") {
+		t.Fail()
+	}
+}
+
+func TestMaxEntriesFIFO(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](
+		memo.WithMaxEntries[int](2),
+		memo.WithEvictionPolicy[int](memo.PolicyFIFO),
+		memo.WithClock[int](clock),
+	)
+
+	c.Set("key1", 1, time.Minute)
+	c.Set("key2", 2, time.Minute)
+	c.Get("key1")
+	c.Set("key3", 3, time.Minute)
+
+	if c.Has("key1") || !c.Has("key2") || !c.Has("key3") {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second)
+	c.Set("key2", 2, time.Minute)
+	c.Set("key4", 4, time.Minute)
+
+	if !c.Has("key2") || c.Has("key3") || !c.Has("key4") {
+		t.Fail()
+	}
+}
+
+func TestSetEvictionPolicy(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](
+		memo.WithMaxEntries[int](2),
+		memo.WithEvictionPolicy[int](memo.PolicyFIFO),
+		memo.WithClock[int](clock),
+	)
+
+	if err := c.SetEvictionPolicy(memo.EvictionPolicy(42)); err == nil {
+		t.Fail()
+	}
+
+	c.Set("key1", 1, time.Minute)
+	clock.Advance(time.Second)
+	c.Set("key2", 2, time.Minute)
+
+	if err := c.SetEvictionPolicy(memo.PolicyLRU); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Second)
+	c.Get("key1")
+	c.Set("key3", 3, time.Minute)
+
+	if !c.Has("key1") || c.Has("key2") || !c.Has("key3") {
+		t.Fail()
+	}
+
+	c.Close()
+	if err := c.SetEvictionPolicy(memo.PolicyFIFO); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSetEvictionPolicyConcurrent(t *testing.T) {
+	c := memo.New[int](memo.WithMaxEntries[int](100))
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i, time.Minute)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Get(strconv.Itoa(j % 10))
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		c.SetEvictionPolicy(memo.EvictionPolicy(i % 2))
+		runtime.Gosched()
+	}
+	wg.Wait()
+}
+
+func TestPin(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithMaxEntries[int](2), memo.WithClock[int](clock))
+
+	c.Set("pinned", 1, time.Minute)
+	if err := c.Pin("pinned"); err != nil {
+		t.Fail()
+	}
+	clock.Advance(time.Second)
+	c.Set("key1", 2, time.Minute)
+	clock.Advance(time.Second)
+	c.Set("key2", 3, time.Minute)
+
+	if !c.Has("pinned") || c.Has("key1") || !c.Has("key2") {
+		t.Fail()
+	}
+
+	c.Pin("key2")
+	if err := c.Set("key3", 4, time.Minute); !errors.Is(err, memo.ErrAllPinned) {
+		t.Fail()
+	}
+
+	if c.Has("key3") || c.Len() != 2 {
+		t.Fail()
+	}
+
+	c.Set("key2", 5, time.Minute)
+	c.Unpin("pinned")
+	c.Set("key3", 4, time.Minute)
+	if c.Has("pinned") || !c.Has("key2") || !c.Has("key3") {
+		t.Fail()
+	}
+
+	if err := c.Pin("missing"); err == nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,384 @@
+package test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/crewcrew23/memo/internal/cache"
+	"github.com/crewcrew23/memo/pkg/memo"
+	"github.com/crewcrew23/memo/pkg/memotest"
+)
+
+func FuzzMarshalRoundTrip(f *testing.F) {
+	f.Add("key1", "value1", "key2", "value2", uint32(60))
+	f.Add("", "", "same", "same", uint32(1))
+	f.Add("ключ", "значение", "\x00", "\"quoted\"", uint32(3600))
+
+	f.Fuzz(func(t *testing.T, key1, value1, key2, value2 string, seconds uint32) {
+		if !utf8.ValidString(key1) || !utf8.ValidString(key2) || !utf8.ValidString(value1) || !utf8.ValidString(value2) {
+			t.Skip("JSON replaces invalid UTF-8")
+		}
+
+		ttl := time.Minute + time.Duration(seconds)*time.Second
+
+		c := memo.New[string]()
+		defer c.Close()
+
+		c.Set(key1, value1, ttl)
+		c.Set(key2, value2, ttl*2)
+
+		bytes, err := c.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uc := memo.New[string]()
+		defer uc.Close()
+
+		if err := uc.UnmarshalJSON(bytes); err != nil {
+			t.Fatal(err)
+		}
+
+		want := c.Dump()
+		got := uc.Dump()
+		if len(want) != len(got) {
+			t.Fatalf("got %d entries, want %d", len(got), len(want))
+		}
+
+		for i := range want {
+			if got[i].Key != want[i].Key || got[i].Value != want[i].Value {
+				t.Fatalf("got %q=%q, want %q=%q", got[i].Key, got[i].Value, want[i].Key, want[i].Value)
+			}
+
+			if !got[i].ExpiresAt.Equal(want[i].ExpiresAt) {
+				t.Fatalf("got expiry %s, want %s", got[i].ExpiresAt, want[i].ExpiresAt)
+			}
+		}
+
+		if uc.Stat().SizeBytes != c.Stat().SizeBytes {
+			t.Fatalf("got size %d, want %d", uc.Stat().SizeBytes, c.Stat().SizeBytes)
+		}
+	})
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func TestSaveRestoreCodec(t *testing.T) {
+	c := memo.New[TestData](memo.WithCodec[TestData](gobCodec{}))
+	c.Set("a", TestData{Value: 1}, time.Minute)
+	c.Set("b", TestData{Value: 2}, time.Minute)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if json.Valid(buf.Bytes()) {
+		t.Fail()
+	}
+
+	restored := memo.New[TestData](memo.WithCodec[TestData](gobCodec{}))
+	if err := restored.Restore(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := restored.Get("b"); err != nil || v.Value != 2 {
+		t.Fail()
+	}
+}
+
+func TestMarshalJSONSorted(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	first := memo.New[int](memo.WithClock[int](clock))
+	second := memo.New[int](memo.WithClock[int](clock))
+
+	keys := []string{"c", "a", "b", "é", "a\"b"}
+	for i := range keys {
+		first.Set(keys[i], i, time.Hour)
+		second.Set(keys[len(keys)-1-i], len(keys)-1-i, time.Hour)
+	}
+
+	a, err := first.MarshalJSONSorted()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := second.MarshalJSONSorted()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, _ := first.MarshalJSON()
+	if !bytes.Equal(a, b) || !bytes.Equal(a, plain) {
+		t.Fail()
+	}
+
+	restored := memo.New[int]()
+	if err := restored.UnmarshalJSON(a); err != nil || len(restored.Dump()) != len(keys) {
+		t.Fail()
+	}
+}
+
+func TestMarshalJSONAndPrune(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.New[int](memo.WithClock[int](clock))
+	c.Set("live", 1, time.Minute)
+	c.Set("expired", 2, time.Second)
+	clock.Advance(time.Second * 2)
+
+	plain, _ := c.MarshalJSON()
+	if c.Stat().Evictions != 0 {
+		t.Fail()
+	}
+
+	pruned, err := c.MarshalJSONAndPrune()
+	if err != nil || !bytes.Equal(plain, pruned) {
+		t.Fail()
+	}
+
+	if c.Stat().Evictions != 1 || len(c.Dump()) != 1 {
+		t.Fail()
+	}
+}
+
+func BenchmarkUnmarshalJSON100k(b *testing.B) {
+	src := memo.New[TestData]()
+	for i := 0; i < 100000; i++ {
+		src.Set(strconv.Itoa(i), TestData{Value: i}, time.Hour)
+	}
+
+	data, err := src.MarshalJSON()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := memo.New[TestData]()
+		if err := c.UnmarshalJSON(data); err != nil {
+			b.Fatal(err)
+		}
+		c.Close()
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	c := memo.New[TestData]()
+	for i := 0; i < 2000; i++ {
+		c.Set(strconv.Itoa(i), TestData{Value: i}, time.Minute)
+	}
+	c.Set("expired", TestData{}, -time.Second)
+
+	var buf bytes.Buffer
+	if err := c.MarshalNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != 2000 {
+		t.Fatal(lines)
+	}
+
+	restored := memo.New[TestData]()
+	if err := restored.UnmarshalNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Len() != 2000 {
+		t.Fail()
+	}
+
+	if v, err := restored.Get("1999"); err != nil || v.Value != 1999 {
+		t.Fail()
+	}
+
+	err := restored.UnmarshalNDJSON(strings.NewReader("{\"key\":\"a\",\"value\":{\"Value\":1},\"ttl\":\"2100-01-01T00:00:00Z\"}\n{broken\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") || !restored.Has("a") {
+		t.Fail()
+	}
+}
+
+type slowJSON struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowJSON) MarshalJSON() ([]byte, error) {
+	close(s.started)
+	<-s.release
+	return []byte(`"slow"`), nil
+}
+
+func TestMarshalJSONDoesNotBlockWriters(t *testing.T) {
+	slow := &slowJSON{started: make(chan struct{}), release: make(chan struct{})}
+	c := memo.New[*slowJSON]()
+	c.Set("slow", slow, time.Minute)
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := c.MarshalJSON()
+		done <- data
+	}()
+	<-slow.started
+
+	written := make(chan struct{})
+	go func() {
+		c.Set("key", &slowJSON{}, time.Minute)
+		close(written)
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("Set blocked by MarshalJSON")
+	}
+	close(slow.release)
+
+	data := <-done
+	if !bytes.Contains(data, []byte(`"slow"`)) || bytes.Contains(data, []byte(`"key"`)) {
+		t.Fail()
+	}
+}
+
+func TestUnmarshalJSONRebased(t *testing.T) {
+	clock := memotest.NewClock(time.Now().Add(-time.Hour * 5))
+	c := memo.New[int](memo.WithClock[int](clock))
+	c.Set("key", 1, time.Minute)
+	c.Set("short", 2, time.Millisecond)
+	clock.Advance(time.Millisecond)
+
+	data, err := c.MarshalJSONSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	restored := memo.New[int]()
+	if err := restored.UnmarshalJSONRebased(data, now); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := restored.Dump()
+	if len(entries) != 1 || !entries[0].ExpiresAt.Equal(now.Add(time.Minute-time.Millisecond)) {
+		t.Fail()
+	}
+
+	plain, _ := c.MarshalJSON()
+	if err := restored.UnmarshalJSONRebased(plain, now); err == nil {
+		t.Fail()
+	}
+}
+
+func TestTTLPrecision(t *testing.T) {
+	clock := memotest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := memo.New[int](memo.WithClock[int](clock), memo.WithTTLPrecision[int](time.Second))
+	defer c.Close()
+
+	c.Set("key", 1, time.Minute)
+	first, err := c.MarshalJSON()
+	if err != nil {
+		t.Fail()
+	}
+
+	clock.Advance(time.Millisecond * 300)
+	c.Set("key", 1, time.Minute)
+	second, _ := c.MarshalJSON()
+	if string(first) != string(second) {
+		t.Fail()
+	}
+
+	clock.Advance(time.Second*59 + time.Millisecond*800)
+	if _, err := c.Get("key"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestMaxAgeOnLoad(t *testing.T) {
+	src := memo.New[int]()
+	defer src.Close()
+	src.Set("key", 1, time.Hour)
+
+	data, _ := src.MarshalJSON()
+	snapshot, _ := src.MarshalJSONSnapshot()
+	var ndjson bytes.Buffer
+	src.MarshalNDJSON(&ndjson)
+	var saved bytes.Buffer
+	src.Save(&saved)
+
+	path := filepath.Join(t.TempDir(), "snapshot")
+	persisted := memo.New[int](memo.WithPersistOnClose[int](path))
+	persisted.Set("key", 1, time.Hour)
+	persisted.Close()
+
+	loads := map[string]func(clock *memotest.Clock) *cache.Cache[int]{
+		"UnmarshalJSON": func(clock *memotest.Clock) *cache.Cache[int] {
+			c := memo.New[int](memo.WithMaxAge[int](time.Minute), memo.WithClock[int](clock))
+			c.UnmarshalJSON(data)
+			return c
+		},
+		"MergeJSON": func(clock *memotest.Clock) *cache.Cache[int] {
+			c := memo.New[int](memo.WithMaxAge[int](time.Minute), memo.WithClock[int](clock))
+			c.MergeJSON(data, memo.Overwrite)
+			return c
+		},
+		"Restore": func(clock *memotest.Clock) *cache.Cache[int] {
+			c := memo.New[int](memo.WithMaxAge[int](time.Minute), memo.WithClock[int](clock))
+			c.Restore(bytes.NewReader(saved.Bytes()))
+			return c
+		},
+		"UnmarshalNDJSON": func(clock *memotest.Clock) *cache.Cache[int] {
+			c := memo.New[int](memo.WithMaxAge[int](time.Minute), memo.WithClock[int](clock))
+			c.UnmarshalNDJSON(bytes.NewReader(ndjson.Bytes()))
+			return c
+		},
+		"UnmarshalJSONRebased": func(clock *memotest.Clock) *cache.Cache[int] {
+			c := memo.New[int](memo.WithMaxAge[int](time.Minute), memo.WithClock[int](clock))
+			c.UnmarshalJSONRebased(snapshot, clock.Now())
+			return c
+		},
+		"WithRestoreOnStart": func(clock *memotest.Clock) *cache.Cache[int] {
+			return memo.New[int](
+				memo.WithMaxAge[int](time.Minute),
+				memo.WithClock[int](clock),
+				memo.WithRestoreOnStart[int](path),
+			)
+		},
+		"From": func(clock *memotest.Clock) *cache.Cache[int] {
+			return memo.From[int](src, memo.WithMaxAge[int](time.Minute), memo.WithClock[int](clock))
+		},
+	}
+
+	for name, load := range loads {
+		t.Run(name, func(t *testing.T) {
+			clock := memotest.NewClock(time.Now())
+			c := load(clock)
+			defer c.Close()
+
+			if _, err := c.Get("key"); err != nil {
+				t.Fatal(err)
+			}
+
+			clock.Advance(time.Minute * 2)
+			if _, err := c.Get("key"); err == nil {
+				t.Fail()
+			}
+		})
+	}
+}
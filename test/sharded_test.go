@@ -0,0 +1,161 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewcrew23/memo/pkg/memo"
+	"github.com/crewcrew23/memo/pkg/memotest"
+)
+
+func TestSharded(t *testing.T) {
+	c := memo.NewSharded[*TestData](memo.WithShardCount[*TestData](3))
+
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), &TestData{i}, time.Second*5)
+	}
+
+	for i := 0; i < 100; i++ {
+		if val, err := c.Get(strconv.Itoa(i)); err != nil || val.Value != i {
+			t.Fail()
+		}
+	}
+
+	if stat := c.Stat(); stat.Hits != 100 || stat.HitRate != 100 {
+		t.Fail()
+	}
+
+	c.Close()
+	if _, err := c.Get("1"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestShardHasher(t *testing.T) {
+	calls := 0
+	c := memo.NewSharded[int](memo.WithShardHasher[int](func(key string) uint64 {
+		calls++
+		return uint64(len(key))
+	}))
+
+	c.Set("key", 1, time.Second*5)
+	if c.GetOrDefault("key", 0) != 1 || calls != 2 {
+		t.Fail()
+	}
+}
+
+func benchmarkParallel(b *testing.B, c memo.Cache[int]) {
+	for i := 0; i < 1024; i++ {
+		c.Set(strconv.Itoa(i), i, time.Hour)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := strconv.Itoa(i % 1024)
+			if i%4 == 0 {
+				c.Set(key, i, time.Hour)
+			} else {
+				c.Get(key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkCacheParallel(b *testing.B) {
+	c := memo.New[int]()
+	defer c.Close()
+
+	benchmarkParallel(b, c)
+}
+
+func BenchmarkShardedParallel(b *testing.B) {
+	c := memo.NewSharded[int](memo.WithShardCount[int](32))
+	defer c.Close()
+
+	benchmarkParallel(b, c)
+}
+
+func TestShardStats(t *testing.T) {
+	c := memo.NewSharded[int](
+		memo.WithShardCount[int](4),
+		memo.WithShardHasher[int](func(key string) uint64 { return uint64(len(key)) }),
+	)
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	c.Set("cc", 3, time.Minute)
+	c.Get("a")
+
+	stats := c.ShardStats()
+	if len(stats) != 4 || stats[1].Entries != 2 || stats[1].Hits != 1 || stats[2].Entries != 1 || stats[0].Entries != 0 {
+		t.Fail()
+	}
+
+	if st := c.Stat(); st.Entries != 3 || st.Hits != 1 {
+		t.Fail()
+	}
+}
+
+func TestShardedKeyTransform(t *testing.T) {
+	c := memo.NewSharded[int](
+		memo.WithShardCount[int](8),
+		memo.WithKeyTransform[int](strings.TrimSpace),
+	)
+	defer c.Close()
+
+	for i := 0; i < 8; i++ {
+		c.Set(" "+strconv.Itoa(i)+" ", i, time.Minute)
+	}
+
+	for i := 0; i < 8; i++ {
+		if val, err := c.Get(strconv.Itoa(i)); err != nil || val != i {
+			t.Fail()
+		}
+	}
+
+	c.Set("0", 10, time.Minute)
+	if st := c.Stat(); st.Entries != 8 {
+		t.Fail()
+	}
+}
+
+func TestShardedRecentHitRate(t *testing.T) {
+	c := memo.NewSharded[int](
+		memo.WithShardCount[int](2),
+		memo.WithShardHasher[int](func(key string) uint64 { return uint64(len(key)) }),
+		memo.WithHitRateWindow[int](time.Millisecond*50, 5),
+	)
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		c.Get("aa")
+	}
+	time.Sleep(time.Millisecond * 100)
+
+	c.Set("aa", 1, time.Minute)
+	c.Get("aa")
+	c.Get("a")
+
+	if st := c.Stat(); st.RecentHitRate != 50 {
+		t.Fail()
+	}
+}
+
+func TestShardStatsExpired(t *testing.T) {
+	clock := memotest.NewClock(time.Now())
+	c := memo.NewSharded[int](memo.WithShardCount[int](1), memo.WithClock[int](clock))
+	defer c.Close()
+
+	c.Set("key", 1, time.Second)
+	clock.Advance(time.Second * 2)
+
+	if st := c.ShardStats()[0]; st.Entries != 0 || st.MapSize != 1 {
+		t.Fail()
+	}
+}